@@ -17,13 +17,24 @@
 package cmd
 
 import (
+	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// completionCacheTimeout is how long the names of containers and images are
+// reused for completion. The shell runs toolbox anew for every completion, and
+// listing containers or images with Podman is slow enough to be noticed when
+// pressing TAB several times in a row.
+const completionCacheTimeout = 5 * time.Second
+
 var completionCmd = &cobra.Command{
 	Use:                   "completion",
 	Short:                 "Generate completion script",
@@ -72,42 +83,33 @@ func completionCommands(cmd *cobra.Command, _ []string, _ string) ([]string, cob
 }
 
 func completionContainerNames(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-	var containerNames []string
-	if containers, err := getContainers(); err == nil {
-		for _, container := range containers {
-			containerNames = append(containerNames, container.Names[0])
-		}
-	}
-
+	containerNames := getContainerNamesForCompletion()
 	return containerNames, cobra.ShellCompDirectiveNoFileComp
 }
 
 func completionContainerNamesFiltered(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
-	if cmd.Name() == "enter" && len(args) >= 1 {
+	if (cmd.Name() == "enter" || cmd.Name() == "logs" || cmd.Name() == "top") && len(args) >= 1 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
 	var containerNames []string
-	if containers, err := getContainers(); err == nil {
-		for _, container := range containers {
-			skip := false
-			for _, arg := range args {
-				if container.Names[0] == arg {
-					skip = true
-					break
-				}
-			}
-
-			if skip {
-				continue
+	for _, containerName := range getContainerNamesForCompletion() {
+		skip := false
+		for _, arg := range args {
+			if containerName == arg {
+				skip = true
+				break
 			}
+		}
 
-			containerNames = append(containerNames, container.Names[0])
+		if skip {
+			continue
 		}
+
+		containerNames = append(containerNames, containerName)
 	}
 
 	return containerNames, cobra.ShellCompDirectiveNoFileComp
-
 }
 
 func completionDistroNames(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -127,48 +129,226 @@ func completionImageNames(cmd *cobra.Command, _ []string, _ string) ([]string, c
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
+	imageNames := getImageNamesForCompletion()
+	return imageNames, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionImageNamesFiltered(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 	var imageNames []string
-	if images, err := getImages(true); err == nil {
-		for _, image := range images {
-			if len(image.Names) != 1 {
-				panic("cannot complete unflattened Image")
+	for _, imageName := range getImageNamesForCompletion() {
+		skip := false
+		for _, arg := range args {
+			if arg == imageName {
+				skip = true
+				break
 			}
+		}
 
-			imageNames = append(imageNames, image.Names[0])
+		if skip {
+			continue
 		}
+
+		imageNames = append(imageNames, imageName)
 	}
 
 	return imageNames, cobra.ShellCompDirectiveNoFileComp
 }
 
-func completionImageNamesFiltered(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
-	var imageNames []string
-	if images, err := getImages(true); err == nil {
-		for _, image := range images {
-			skip := false
+func completionInspectTargets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var targets []string
+
+	typeArg, _ := cmd.Flags().GetString("type")
+	if typeArg == "" || typeArg == "container" {
+		containerNames, _ := completionContainerNames(cmd, args, toComplete)
+		targets = append(targets, containerNames...)
+	}
+
+	if typeArg == "" || typeArg == "image" {
+		imageNames, _ := completionImageNamesFiltered(cmd, args, toComplete)
+		targets = append(targets, imageNames...)
+	}
+
+	return targets, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionInspectTypes(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"container", "image"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionListFormats(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"csv", "json", "table", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionListSortFields(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"created", "names"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionLogLevels(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// getContainerNamesForCompletion returns the names of the toolbox containers,
+// cached as explained for getCompletionNames.
+func getContainerNamesForCompletion() []string {
+	return getCompletionNames("containers", func() ([]string, error) {
+		containers, err := getContainers(context.Background(), false)
+		if err != nil {
+			return nil, err
+		}
+
+		var containerNames []string
+		for _, container := range containers {
+			containerNames = append(containerNames, container.Names[0])
+		}
+
+		return containerNames, nil
+	})
+}
+
+// getImageNamesForCompletion returns the names of the toolbox images, cached as
+// explained for getCompletionNames.
+func getImageNamesForCompletion() []string {
+	return getCompletionNames("images", func() ([]string, error) {
+		images, err := getImages(context.Background(), true, false)
+		if err != nil {
+			return nil, err
+		}
 
+		var imageNames []string
+		for _, image := range images {
 			if len(image.Names) != 1 {
 				panic("cannot complete unflattened Image")
 			}
 
-			for _, arg := range args {
-				if arg == image.Names[0] {
-					skip = true
-					break
-				}
-			}
+			imageNames = append(imageNames, image.Names[0])
+		}
 
-			if skip {
-				continue
-			}
+		return imageNames, nil
+	})
+}
 
-			imageNames = append(imageNames, image.Names[0])
+// getCompletionNames returns the names cached in the runtime directory under
+// key, if they were cached less than completionCacheTimeout ago. Otherwise,
+// it gets them anew with getNames and caches them. Names that can't be
+// cached are still returned, and errors are only logged, because there's no
+// way to show them during completion.
+func getCompletionNames(key string, getNames func() ([]string, error)) []string {
+	cachePath := getCompletionCachePath(key)
+
+	if cachePath != "" {
+		if names, ok := readCompletionCache(cachePath, time.Now()); ok {
+			return names
 		}
 	}
 
-	return imageNames, cobra.ShellCompDirectiveNoFileComp
+	names, err := getNames()
+	if err != nil {
+		logrus.Debugf("Getting the names for completion failed: %s", err)
+		return nil
+	}
+
+	if cachePath != "" {
+		if err := writeCompletionCache(cachePath, names); err != nil {
+			logrus.Debugf("Caching the names for completion failed: %s", err)
+		}
+	}
+
+	return names
 }
 
-func completionLogLevels(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-	return []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}, cobra.ShellCompDirectiveNoFileComp
+// getCompletionCachePath returns the path of the cache file for key in the
+// runtime directory, or an empty string if there's no runtime directory.
+func getCompletionCachePath(key string) string {
+	if currentUser == nil {
+		return ""
+	}
+
+	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(currentUser)
+	if err != nil {
+		return ""
+	}
+
+	cacheFile := "completion-" + key
+	return filepath.Join(toolboxRuntimeDirectory, cacheFile)
+}
+
+// removeCompletionCache removes the names cached under the given keys, so
+// that completion doesn't offer the containers and images that were just
+// removed, or leave out those that were just created. Errors are only logged.
+func removeCompletionCache(keys ...string) {
+	for _, key := range keys {
+		cachePath := getCompletionCachePath(key)
+		if cachePath == "" {
+			continue
+		}
+
+		if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
+			logrus.Debugf("Removing the names cached for completion failed: %s", err)
+		}
+	}
+}
+
+// readCompletionCache reads the names, one per line, from the cache file at
+// path. It returns false if the file doesn't exist or is older than
+// completionCacheTimeout at the time now.
+func readCompletionCache(path string, now time.Time) ([]string, bool) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	age := now.Sub(fileInfo.ModTime())
+	if age < 0 || age > completionCacheTimeout {
+		return nil, false
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var names []string
+	for _, name := range strings.Split(string(data), "\n") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, true
+}
+
+// writeCompletionCache writes the names, one per line, to the cache file at
+// path. The file is replaced atomically, so that a completion running at the
+// same time never reads half of it.
+func writeCompletionCache(path string, names []string) error {
+	directory := filepath.Dir(path)
+	base := filepath.Base(path)
+
+	file, err := ioutil.TempFile(directory, base)
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteString("\n")
+	}
+
+	if _, err := file.WriteString(builder.String()); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
 }
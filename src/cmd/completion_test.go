@@ -0,0 +1,111 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "completion-containers")
+
+	_, ok := readCompletionCache(path, time.Now())
+	assert.False(t, ok)
+
+	err := writeCompletionCache(path, []string{"fedora-toolbox-36", "gegl"})
+	require.NoError(t, err)
+
+	names, ok := readCompletionCache(path, time.Now())
+	assert.True(t, ok)
+	assert.Equal(t, []string{"fedora-toolbox-36", "gegl"}, names)
+
+	_, ok = readCompletionCache(path, time.Now().Add(completionCacheTimeout+time.Second))
+	assert.False(t, ok)
+
+	err = writeCompletionCache(path, nil)
+	require.NoError(t, err)
+
+	names, ok = readCompletionCache(path, time.Now())
+	assert.True(t, ok)
+	assert.Empty(t, names)
+
+	matches, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, matches)
+}
+
+// setUpCompletionCache makes the completion cache live in a temporary runtime
+// directory, and returns the path of the cache file for key.
+func setUpCompletionCache(t *testing.T, key string) string {
+	uid := os.Getuid()
+	if uid == 0 {
+		// The runtime directory of root is always /run.
+		uid = 1000
+	}
+
+	defaultUser := currentUser
+	defaultRuntimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	t.Cleanup(func() {
+		currentUser = defaultUser
+		os.Setenv("XDG_RUNTIME_DIR", defaultRuntimeDir)
+	})
+
+	currentUser = &user.User{Uid: strconv.Itoa(uid), Gid: strconv.Itoa(os.Getgid())}
+	os.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	cachePath := getCompletionCachePath(key)
+	require.NotEmpty(t, cachePath)
+	return cachePath
+}
+
+func TestRemoveCompletionCache(t *testing.T) {
+	containersPath := setUpCompletionCache(t, "containers")
+	imagesPath := getCompletionCachePath("images")
+
+	err := writeCompletionCache(containersPath, []string{"fedora-toolbox-36"})
+	require.NoError(t, err)
+	err = writeCompletionCache(imagesPath, []string{"fedora-toolbox:36"})
+	require.NoError(t, err)
+
+	removeCompletionCache("containers")
+	assert.NoFileExists(t, containersPath)
+	assert.FileExists(t, imagesPath)
+
+	// A missing cache file isn't a problem.
+	removeCompletionCache("containers", "images")
+	assert.NoFileExists(t, imagesPath)
+}
+
+func TestGetContainerNamesForCompletion(t *testing.T) {
+	shelltest.SetUp(t, podman.SetRunner, shelltest.Output(`[
+  {"Id": "4c9f5ce5f4d4", "Names": ["fedora-toolbox-36"], "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "d5e0b4e3c6b1", "Names": ["web-server"], "Labels": null}
+]`))
+
+	names := getContainerNamesForCompletion()
+	assert.Equal(t, []string{"fedora-toolbox-36"}, names)
+}
@@ -19,14 +19,16 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/containers/toolbox/pkg/podman"
-	"github.com/containers/toolbox/pkg/shell"
 	"github.com/containers/toolbox/pkg/skopeo"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/docker/go-units"
@@ -44,11 +46,15 @@ const (
 
 var (
 	createFlags struct {
-		authFile  string
-		container string
-		distro    string
-		image     string
-		release   string
+		authFile      string
+		container     string
+		distro        string
+		image         string
+		labels        []string
+		passwordStdin bool
+		platform      string
+		release       string
+		username      string
 	}
 
 	createToolboxShMounts = []struct {
@@ -93,12 +99,32 @@ func init() {
 		"",
 		"Change the name of the base image used to create the toolbox container")
 
+	flags.StringArrayVar(&createFlags.labels,
+		"label",
+		nil,
+		"Add a label in format KEY=VALUE to the toolbox container, can be repeated")
+
+	flags.BoolVar(&createFlags.passwordStdin,
+		"password-stdin",
+		false,
+		"Read the password for the registry from the standard input")
+
+	flags.StringVar(&createFlags.platform,
+		"platform",
+		"",
+		"Use the image for a different platform than the host, in format OS/ARCH[/VARIANT]")
+
 	flags.StringVarP(&createFlags.release,
 		"release",
 		"r",
 		"",
 		"Create a toolbox container for a different operating system release than the host")
 
+	flags.StringVar(&createFlags.username,
+		"username",
+		"",
+		"User name for authenticating to the registry for private images")
+
 	createCmd.SetHelpFunc(createHelp)
 
 	if err := createCmd.RegisterFlagCompletionFunc("distro", completionDistroNames); err != nil {
@@ -157,6 +183,50 @@ func create(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if cmd.Flag("authfile").Changed && cmd.Flag("username").Changed {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "options --authfile and --username cannot be used together\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if createFlags.passwordStdin && createFlags.username == "" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "option --password-stdin requires --username\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if createFlags.username != "" && !createFlags.passwordStdin && !isStdinTerminal() {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "option --username requires --password-stdin without a terminal\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	labels, err := parseCreateLabels(createFlags.labels)
+	if err != nil {
+		return err
+	}
+
+	if createFlags.platform != "" {
+		if err := validateCreatePlatform(createFlags.platform); err != nil {
+			return err
+		}
+	}
+
+	pullOptions := podman.PullOptions{
+		AuthFile: createFlags.authFile,
+		Platform: createFlags.platform,
+		Username: createFlags.username,
+	}
+
 	var container string
 	var containerArg string
 
@@ -178,14 +248,17 @@ func create(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := createContainer(container, image, release, createFlags.authFile, true); err != nil {
+	if err := createContainer(container, image, release, pullOptions, labels, true); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func createContainer(container, image, release, authFile string, showCommandToEnter bool) error {
+func createContainer(container, image, release string,
+	pullOptions podman.PullOptions,
+	labels map[string]string,
+	showCommandToEnter bool) error {
 	if container == "" {
 		panic("container not specified")
 	}
@@ -212,7 +285,7 @@ func createContainer(container, image, release, authFile string, showCommandToEn
 		return errors.New(errMsg)
 	}
 
-	pulled, err := pullImage(image, release, authFile)
+	pulled, err := pullImage(image, release, pullOptions)
 	if err != nil {
 		return err
 	}
@@ -371,8 +444,6 @@ func createContainer(container, image, release, authFile string, showCommandToEn
 		slashHomeLink = []string{"--home-link"}
 	}
 
-	logLevelString := podman.LogLevel.String()
-
 	userShell := os.Getenv("SHELL")
 	if userShell == "" {
 		return errors.New("failed to get the current user's default shell")
@@ -393,8 +464,6 @@ func createContainer(container, image, release, authFile string, showCommandToEn
 	entryPoint = append(entryPoint, mntLink...)
 
 	createArgs := []string{
-		"--log-level", logLevelString,
-		"create",
 		"--cgroupns", "host",
 		"--dns", "none",
 		"--env", toolboxPathEnvArg,
@@ -405,9 +474,10 @@ func createContainer(container, image, release, authFile string, showCommandToEn
 	createArgs = append(createArgs, []string{
 		"--hostname", "toolbox",
 		"--ipc", "host",
-		"--label", "com.github.containers.toolbox=true",
 	}...)
 
+	createArgs = append(createArgs, getCreateLabelArgs(labels)...)
+
 	createArgs = append(createArgs, devPtsMount...)
 
 	createArgs = append(createArgs, []string{
@@ -443,7 +513,7 @@ func createContainer(container, image, release, authFile string, showCommandToEn
 	createArgs = append(createArgs, entryPoint...)
 
 	logrus.Debugf("Creating container %s:", container)
-	logrus.Debug("podman")
+	logrus.Debugf("%s create", containerEngine.Name())
 	for _, arg := range createArgs {
 		logrus.Debugf("%s", arg)
 	}
@@ -459,10 +529,13 @@ func createContainer(container, image, release, authFile string, showCommandToEn
 		defer s.Stop()
 	}
 
-	if err := shell.Run("podman", nil, nil, nil, createArgs...); err != nil {
+	if err := containerEngine.Create(createArgs); err != nil {
+		logrus.Debugf("Creating container %s failed: %s", container, err)
 		return fmt.Errorf("failed to create container %s", container)
 	}
 
+	removeCompletionCache("containers", "images")
+
 	// The spinner must be stopped before showing the 'enter' hint below.
 	s.Stop()
 
@@ -495,6 +568,26 @@ func createHelp(cmd *cobra.Command, args []string) {
 	}
 }
 
+// getCreateLabelArgs returns the '--label' options for 'podman create'. The
+// label that marks a toolbox container always comes first, followed by the
+// user's labels sorted by key.
+func getCreateLabelArgs(labels map[string]string) []string {
+	args := []string{"--label", "com.github.containers.toolbox=true"}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		args = append(args, "--label", key+"="+labels[key])
+	}
+
+	return args
+}
+
 func getDBusSystemSocket() (string, error) {
 	logrus.Debug("Resolving path to the D-Bus system socket")
 
@@ -542,7 +635,7 @@ func getFullyQualifiedImageFromRepoTags(image string) (string, error) {
 	if utils.ImageReferenceHasDomain(image) {
 		imageFull = image
 	} else {
-		info, err := podman.Inspect("image", image)
+		info, err := containerEngine.Inspect("image", image)
 		if err != nil {
 			return "", fmt.Errorf("failed to inspect image %s", image)
 		}
@@ -658,11 +751,75 @@ func getServiceSocket(serviceName string, unitName string) (string, error) {
 	return "", fmt.Errorf("failed to find a SOCK_STREAM socket for %s", unitName)
 }
 
-func pullImage(image, release, authFile string) (bool, error) {
+// parseCreateLabels parses the values of '--label' in format KEY=VALUE, or
+// just KEY for an empty value. The labels that mark a toolbox container are
+// reserved, so that they can't be dropped or changed by the user.
+func parseCreateLabels(values []string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, value := range values {
+		key, labelValue := value, ""
+		if i := strings.Index(value, "="); i != -1 {
+			key, labelValue = value[:i], value[i+1:]
+		}
+
+		if key == "" {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for '--label'\n")
+			fmt.Fprintf(&builder, "Label %s is not in format KEY=VALUE\n", value)
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return nil, errors.New(errMsg)
+		}
+
+		if key == "com.github.containers.toolbox" || key == "com.github.debarshiray.toolbox" {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for '--label'\n")
+			fmt.Fprintf(&builder, "Label %s is reserved for Toolbox\n", key)
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return nil, errors.New(errMsg)
+		}
+
+		labels[key] = labelValue
+	}
+
+	return labels, nil
+}
+
+// imageExistsForPlatform checks if an image is in local storage and, unless
+// platform is empty, if it is for that platform. A platform without a variant
+// matches images for any variant.
+func imageExistsForPlatform(image, platform string) bool {
+	if _, err := podman.ImageExists(image); err != nil {
+		return false
+	}
+
+	if platform == "" {
+		return true
+	}
+
+	imagePlatform, err := podman.GetImagePlatform(image)
+	if err != nil {
+		logrus.Debugf("Getting the platform of image %s failed: %s", image, err)
+		return false
+	}
+
+	if imagePlatform == platform || strings.HasPrefix(imagePlatform, platform+"/") {
+		return true
+	}
+
+	logrus.Debugf("Image %s is for platform %s instead of %s", image, imagePlatform, platform)
+	return false
+}
+
+func pullImage(image, release string, pullOptions podman.PullOptions) (bool, error) {
 	if ok := utils.ImageReferenceCanBeID(image); ok {
 		logrus.Debugf("Looking up image %s", image)
 
-		if _, err := podman.ImageExists(image); err == nil {
+		if imageExistsForPlatform(image, pullOptions.Platform) {
 			return true, nil
 		}
 	}
@@ -673,7 +830,7 @@ func pullImage(image, release, authFile string) (bool, error) {
 		imageLocal := "localhost/" + image
 		logrus.Debugf("Looking up image %s", imageLocal)
 
-		if _, err := podman.ImageExists(imageLocal); err == nil {
+		if imageExistsForPlatform(imageLocal, pullOptions.Platform) {
 			return true, nil
 		}
 	}
@@ -692,7 +849,7 @@ func pullImage(image, release, authFile string) (bool, error) {
 
 	logrus.Debugf("Looking up image %s", imageFull)
 
-	if _, err := podman.ImageExists(imageFull); err == nil {
+	if imageExistsForPlatform(imageFull, pullOptions.Platform) {
 		return true, nil
 	}
 
@@ -710,6 +867,17 @@ func pullImage(image, release, authFile string) (bool, error) {
 		shouldPullImage = true
 	}
 
+	// The standard input holds the password, which mustn't be taken for
+	// the answer.
+	if promptForDownload && createFlags.passwordStdin {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "image %s needs to be downloaded\n", imageFull)
+		fmt.Fprintf(&builder, "Use option --assumeyes with --password-stdin to download it.")
+
+		errMsg := builder.String()
+		return false, errors.New(errMsg)
+	}
+
 	if promptForDownload {
 		fmt.Println("Image required to create toolbox container.")
 
@@ -729,6 +897,17 @@ func pullImage(image, release, authFile string) (bool, error) {
 		return false, nil
 	}
 
+	// The password is only asked for once it's known that the image will
+	// be pulled, because it's not needed otherwise.
+	if pullOptions.Username != "" && pullOptions.Password == "" {
+		password, err := getRegistryPassword(createFlags.passwordStdin)
+		if err != nil {
+			return false, err
+		}
+
+		pullOptions.Password = password
+	}
+
 	logrus.Debugf("Pulling image %s", imageFull)
 
 	stdoutFd := os.Stdout.Fd()
@@ -739,9 +918,29 @@ func pullImage(image, release, authFile string) (bool, error) {
 		s.Writer = os.Stdout
 		s.Start()
 		defer s.Stop()
+
+		var copying, skipped int
+		pullOptions.Progress = func(progress podman.PullProgress) {
+			if progress.Skipped {
+				skipped++
+			} else {
+				copying++
+			}
+
+			s.Lock()
+			s.Suffix = " " + formatPullProgress(copying, skipped)
+			s.Unlock()
+		}
 	}
 
-	if err := podman.Pull(imageFull, authFile); err != nil {
+	ctx, stop := newInterruptibleContext()
+	defer stop()
+
+	if err := containerEngine.Pull(ctx, imageFull, pullOptions); err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("pulling image %s was interrupted", imageFull)
+		}
+
 		var builder strings.Builder
 		fmt.Fprintf(&builder, "failed to pull image %s\n", imageFull)
 		fmt.Fprintf(&builder, "If it was a private image, log in with: podman login %s\n", domain)
@@ -754,6 +953,87 @@ func pullImage(image, release, authFile string) (bool, error) {
 	return true, nil
 }
 
+// getRegistryPassword reads the password for the registry from the standard
+// input if fromStdin is true, or else asks for it on the terminal. The prompt
+// goes to the standard error, so that it doesn't end up in the output.
+func getRegistryPassword(fromStdin bool) (string, error) {
+	if fromStdin {
+		return readPassword(os.Stdin)
+	}
+
+	fmt.Fprintf(os.Stderr, "Password: ")
+
+	stdinFd := os.Stdin.Fd()
+	stdinFdInt := int(stdinFd)
+	password, err := term.ReadPassword(stdinFdInt)
+	fmt.Fprintf(os.Stderr, "\n")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	return string(password), nil
+}
+
+// readPassword reads a password from in, without the line break that ends
+// it, if there is one.
+func readPassword(in io.Reader) (string, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", errors.New("password is empty")
+	}
+
+	return password, nil
+}
+
+// formatPullProgress describes how many layers of an image are being copied
+// and how many were already present, for the spinner shown while pulling it.
+func formatPullProgress(copying, skipped int) string {
+	var parts []string
+
+	if copying == 1 {
+		parts = append(parts, "copying 1 layer")
+	} else if copying > 1 {
+		parts = append(parts, fmt.Sprintf("copying %d layers", copying))
+	}
+
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d already present", skipped))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// validateCreatePlatform checks that the value of '--platform' is in format
+// OS/ARCH[/VARIANT], like linux/arm64 or linux/arm/v7.
+func validateCreatePlatform(platform string) error {
+	parts := strings.Split(platform, "/")
+	valid := len(parts) == 2 || len(parts) == 3
+
+	for _, part := range parts {
+		if part == "" {
+			valid = false
+		}
+	}
+
+	if !valid {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--platform'\n")
+		fmt.Fprintf(&builder, "Platform %s is not in format OS/ARCH[/VARIANT]\n", platform)
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
 // systemdNeedsEscape checks whether a byte in a potential dbus ObjectPath needs to be escaped
 func systemdNeedsEscape(i int, b byte) bool {
 	// Escape everything that is not a-z-A-Z-0-9
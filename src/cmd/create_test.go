@@ -0,0 +1,272 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateLabels(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []string
+		expected []string
+		errMsg   string
+	}{
+		{
+			name:     "none",
+			expected: []string{"--label", "com.github.containers.toolbox=true"},
+		},
+		{
+			name:   "user labels",
+			values: []string{"project=gegl", "owner=rishi", "empty", "url=https://gegl.org/?a=b"},
+			expected: []string{
+				"--label", "com.github.containers.toolbox=true",
+				"--label", "empty=",
+				"--label", "owner=rishi",
+				"--label", "project=gegl",
+				"--label", "url=https://gegl.org/?a=b",
+			},
+		},
+		{
+			name:   "toolbox label",
+			values: []string{"com.github.containers.toolbox=false"},
+			errMsg: "invalid argument for '--label'\n" +
+				"Label com.github.containers.toolbox is reserved for Toolbox\n" +
+				"Run '" + executableBase + " --help' for usage.",
+		},
+		{
+			name:   "legacy toolbox label",
+			values: []string{"com.github.debarshiray.toolbox="},
+			errMsg: "invalid argument for '--label'\n" +
+				"Label com.github.debarshiray.toolbox is reserved for Toolbox\n" +
+				"Run '" + executableBase + " --help' for usage.",
+		},
+		{
+			name:   "missing key",
+			values: []string{"=gegl"},
+			errMsg: "invalid argument for '--label'\n" +
+				"Label =gegl is not in format KEY=VALUE\n" +
+				"Run '" + executableBase + " --help' for usage.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels, err := parseCreateLabels(tc.values)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, getCreateLabelArgs(labels))
+		})
+	}
+}
+
+func TestFormatPullProgress(t *testing.T) {
+	testCases := []struct {
+		copying  int
+		skipped  int
+		expected string
+	}{
+		{0, 0, ""},
+		{1, 0, "copying 1 layer"},
+		{3, 0, "copying 3 layers"},
+		{0, 2, "2 already present"},
+		{3, 2, "copying 3 layers, 2 already present"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatPullProgress(tc.copying, tc.skipped))
+		})
+	}
+}
+
+func TestReadPassword(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		errMsg   string
+	}{
+		{
+			name:     "with line break",
+			input:    "hunter2\n",
+			expected: "hunter2",
+		},
+		{
+			name:     "with CRLF",
+			input:    "hunter2\r\n",
+			expected: "hunter2",
+		},
+		{
+			name:     "without line break",
+			input:    "hunter2",
+			expected: "hunter2",
+		},
+		{
+			name:     "with spaces",
+			input:    " hunter 2 \n",
+			expected: " hunter 2 ",
+		},
+		{
+			name:   "empty",
+			input:  "\n",
+			errMsg: "password is empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			password, err := readPassword(strings.NewReader(tc.input))
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, password)
+		})
+	}
+}
+
+func TestValidateCreatePlatform(t *testing.T) {
+	testCases := []struct {
+		platform string
+		valid    bool
+	}{
+		{"linux/arm64", true},
+		{"linux/arm/v7", true},
+		{"linux", false},
+		{"linux/", false},
+		{"/arm64", false},
+		{"linux/arm/v7/extra", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.platform, func(t *testing.T) {
+			err := validateCreatePlatform(tc.platform)
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+
+			expected := "invalid argument for '--platform'\n" +
+				"Platform " + tc.platform + " is not in format OS/ARCH[/VARIANT]\n" +
+				"Run '" + executableBase + " --help' for usage."
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
+
+func TestImageExistsForPlatform(t *testing.T) {
+	shelltest.SetUp(t, podman.SetRunner, func(call shelltest.Call) int {
+		if call.Arg(3) == "image" && call.Arg(4) == "exists" {
+			if call.Arg(5) == "fedora-toolbox:36" {
+				return 0
+			}
+
+			return 1
+		}
+
+		if call.Arg(3) == "inspect" {
+			fmt.Fprintln(call.Stdout, `[{"Id": "8b9affd1dbc2", "Os": "linux", "Architecture": "arm64", "Variant": "v8"}]`)
+			return 0
+		}
+
+		return 1
+	})
+
+	testCases := []struct {
+		name     string
+		image    string
+		platform string
+		expected bool
+	}{
+		{"any platform", "fedora-toolbox:36", "", true},
+		{"same platform", "fedora-toolbox:36", "linux/arm64/v8", true},
+		{"platform without variant", "fedora-toolbox:36", "linux/arm64", true},
+		{"other platform", "fedora-toolbox:36", "linux/amd64", false},
+		{"other variant", "fedora-toolbox:36", "linux/arm64/v9", false},
+		{"missing image", "fedora-toolbox:35", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, imageExistsForPlatform(tc.image, tc.platform))
+		})
+	}
+}
+
+func TestPullImageReadsPasswordOnlyForPull(t *testing.T) {
+	shelltest.SetUp(t, podman.SetRunner, func(call shelltest.Call) int {
+		if call.Arg(3) == "image" && call.Arg(4) == "exists" && call.Arg(5) == "registry.example.com/present" {
+			return 0
+		}
+
+		return 1
+	})
+
+	defaultPasswordStdin := createFlags.passwordStdin
+	defaultStdin := os.Stdin
+	t.Cleanup(func() {
+		createFlags.passwordStdin = defaultPasswordStdin
+		os.Stdin = defaultStdin
+	})
+
+	passwordPath := filepath.Join(t.TempDir(), "password")
+	err := ioutil.WriteFile(passwordPath, []byte("secret\n"), 0600)
+	require.NoError(t, err)
+
+	stdin, err := os.Open(passwordPath)
+	require.NoError(t, err)
+	defer stdin.Close()
+
+	os.Stdin = stdin
+	createFlags.passwordStdin = true
+	pullOptions := podman.PullOptions{Username: "foo"}
+
+	t.Run("image present", func(t *testing.T) {
+		pulled, err := pullImage("registry.example.com/present", "", pullOptions)
+		assert.NoError(t, err)
+		assert.True(t, pulled)
+	})
+
+	t.Run("download without --assumeyes", func(t *testing.T) {
+		_, err := pullImage("registry.example.com/missing", "", pullOptions)
+		assert.EqualError(t, err, "image registry.example.com/missing needs to be downloaded\n"+
+			"Use option --assumeyes with --password-stdin to download it.")
+	})
+
+	offset, err := stdin.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inspectFlags struct {
+		typeArg string
+	}
+)
+
+var inspectCmd = &cobra.Command{
+	Use:               "inspect",
+	Short:             "Display detailed information about a toolbox container or image",
+	RunE:              inspect,
+	ValidArgsFunction: completionInspectTargets,
+}
+
+func init() {
+	flags := inspectCmd.Flags()
+
+	flags.StringVarP(&inspectFlags.typeArg,
+		"type",
+		"t",
+		"",
+		"Inspect only a toolbox container or only a toolbox image (container or image)")
+
+	if err := inspectCmd.RegisterFlagCompletionFunc("type", completionInspectTypes); err != nil {
+		panicMsg := fmt.Sprintf("failed to register flag completion function: %v", err)
+		panic(panicMsg)
+	}
+
+	inspectCmd.SetHelpFunc(inspectHelp)
+	rootCmd.AddCommand(inspectCmd)
+}
+
+func inspect(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if inspectFlags.typeArg != "" && inspectFlags.typeArg != "container" && inspectFlags.typeArg != "image" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--type'\n")
+		fmt.Fprintf(&builder, "Supported values are: container, image\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if len(args) != 1 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "inspect requires exactly one container or image\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	return inspectOutput(os.Stdout, inspectFlags.typeArg, args[0])
+}
+
+func inspectHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-inspect"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// getInspectType returns the type of target, either "container" or "image".
+// A container takes precedence over an image with the same name, like it does
+// for 'podman inspect'.
+func getInspectType(typeArg, target string) (string, error) {
+	if typeArg == "" || typeArg == "container" {
+		if exists, _ := podman.ContainerExists(target); exists {
+			return "container", nil
+		}
+	}
+
+	if typeArg == "" || typeArg == "image" {
+		if exists, _ := podman.ImageExists(target); exists {
+			return "image", nil
+		}
+	}
+
+	if typeArg == "" {
+		return "", fmt.Errorf("container or image %s does not exist", target)
+	}
+
+	return "", fmt.Errorf("%s %s does not exist", typeArg, target)
+}
+
+func inspectOutput(writer io.Writer, typeArg, target string) error {
+	typeArg, err := getInspectType(typeArg, target)
+	if err != nil {
+		return err
+	}
+
+	logrus.Debugf("Inspecting %s %s", typeArg, target)
+
+	switch typeArg {
+	case "container":
+		if _, err := podman.IsToolboxContainer(target); err != nil {
+			return err
+		}
+	case "image":
+		if _, err := podman.IsToolboxImage(target); err != nil {
+			return err
+		}
+	default:
+		panic("code should not be reached")
+	}
+
+	info, err := containerEngine.Inspect(typeArg, target)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s %s", typeArg, target)
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s %s: %w", typeArg, target, err)
+	}
+
+	fmt.Fprintln(writer, string(data))
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeInspectPodman(call shelltest.Call) int {
+	switch call.Arg(3) + " " + call.Arg(4) {
+	case "container exists":
+		if call.Arg(5) == "fedora-toolbox-36" || call.Arg(5) == "plain-container" {
+			return 0
+		}
+
+		return 1
+	case "image exists":
+		if call.Arg(5) == "fedora-toolbox:36" || call.Arg(5) == "plain-image" {
+			return 0
+		}
+
+		return 1
+	}
+
+	if call.Arg(3) != "inspect" {
+		return 1
+	}
+
+	switch call.Arg(8) {
+	case "fedora-toolbox-36":
+		fmt.Fprintln(call.Stdout,
+			`[{"Id": "6d3b9a2f", "Name": "fedora-toolbox-36", "Config": {"Labels": {"com.github.containers.toolbox": "true"}}}]`)
+	case "plain-container":
+		fmt.Fprintln(call.Stdout, `[{"Id": "c0ffee00", "Name": "plain-container", "Config": {"Labels": {}}}]`)
+	case "fedora-toolbox:36":
+		fmt.Fprintln(call.Stdout, `[{"Id": "0a1b2c3d", "Labels": {"com.github.containers.toolbox": "true"}}]`)
+	case "plain-image":
+		fmt.Fprintln(call.Stdout, `[{"Id": "deadbeef", "Labels": null}]`)
+	default:
+		fmt.Fprintf(call.Stderr, "Error: no such object: \"%s\"\n", call.Arg(8))
+		return 125
+	}
+
+	return 0
+}
+
+func TestInspectOutput(t *testing.T) {
+	testCases := []struct {
+		name    string
+		typeArg string
+		target  string
+		id      string
+		errMsg  string
+	}{
+		{
+			name:   "container",
+			target: "fedora-toolbox-36",
+			id:     "6d3b9a2f",
+		},
+		{
+			name:   "image",
+			target: "fedora-toolbox:36",
+			id:     "0a1b2c3d",
+		},
+		{
+			name:    "image with --type",
+			typeArg: "image",
+			target:  "fedora-toolbox:36",
+			id:      "0a1b2c3d",
+		},
+		{
+			name:   "non-existent",
+			target: "foo",
+			errMsg: "container or image foo does not exist",
+		},
+		{
+			name:    "non-existent container with --type",
+			typeArg: "container",
+			target:  "fedora-toolbox:36",
+			errMsg:  "container fedora-toolbox:36 does not exist",
+		},
+		{
+			name:   "non-toolbox container",
+			target: "plain-container",
+			errMsg: "plain-container is not a toolbox container",
+		},
+		{
+			name:   "non-toolbox image",
+			target: "plain-image",
+			errMsg: "plain-image is not a toolbox image",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, podman.SetRunner, fakeInspectPodman)
+
+			var out bytes.Buffer
+			err := inspectOutput(&out, tc.typeArg, tc.target)
+
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				assert.Empty(t, out.String())
+				return
+			}
+
+			require.NoError(t, err)
+
+			var document map[string]interface{}
+			err = json.Unmarshal(out.Bytes(), &document)
+			require.NoError(t, err)
+			assert.Equal(t, tc.id, document["Id"])
+		})
+	}
+}
@@ -17,39 +17,126 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/containers/toolbox/pkg/podman"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
+	"gopkg.in/yaml.v2"
 )
 
-type toolboxContainer struct {
-	ID      string
-	Names   []string
-	Status  string
-	Created string
-	Image   string
-	Labels  map[string]string
+// listDocument is the document printed by 'toolbox list --format json' or
+// '--format yaml'. The counts reflect all the containers and images that
+// matched, even if only a page of them is listed.
+type listDocument struct {
+	Images         []podman.Image     `json:"images"`
+	Containers     []podman.Container `json:"containers"`
+	ImageCount     int                `json:"imageCount"`
+	ContainerCount int                `json:"containerCount"`
+	RunningCount   int                `json:"runningCount"`
+}
+
+// listColumn describes a column of the tables printed by 'toolbox list'. An
+// empty header means that the column doesn't apply to that table.
+type listColumn struct {
+	imageHeader     string
+	image           func(podman.Image) string
+	containerHeader string
+	container       func(podman.Container) string
 }
 
 var (
+	// listWatchDelay is how long 'toolbox list --watch' waits after a
+	// change before listing again.
+	listWatchDelay = 200 * time.Millisecond
+
+	// listWatchRefreshInterval is how often 'toolbox list --watch' lists
+	// again even if nothing changed.
+	listWatchRefreshInterval = time.Minute
+)
+
+var (
+	listColumns = map[string]listColumn{
+		"command": {
+			containerHeader: "COMMAND",
+			container:       func(container podman.Container) string { return formatCommand(container.Command) },
+		},
+		"created": {
+			imageHeader:     "CREATED",
+			image:           func(image podman.Image) string { return formatCreated(image.CreatedAt, image.Created) },
+			containerHeader: "CREATED",
+			container: func(container podman.Container) string {
+				return formatCreated(container.CreatedAt, container.Created)
+			},
+		},
+		"id": {
+			imageHeader:     "IMAGE ID",
+			image:           func(image podman.Image) string { return utils.ShortID(image.ID) },
+			containerHeader: "CONTAINER ID",
+			container:       func(container podman.Container) string { return utils.ShortID(container.ID) },
+		},
+		"image": {
+			containerHeader: "IMAGE NAME",
+			container:       func(container podman.Container) string { return formatContainerImage(container) },
+		},
+		"labels": {
+			imageHeader:     "LABELS",
+			image:           func(image podman.Image) string { return formatLabels(image.Labels) },
+			containerHeader: "LABELS",
+			container:       func(container podman.Container) string { return formatLabels(container.Labels) },
+		},
+		"name": {
+			imageHeader:     "IMAGE NAME",
+			image:           func(image podman.Image) string { return formatImageName(image) },
+			containerHeader: "CONTAINER NAME",
+			container:       func(container podman.Container) string { return formatContainerName(container) },
+		},
+		"platform": {
+			imageHeader: "PLATFORM",
+			image:       func(image podman.Image) string { return formatPlatform(image.Platform) },
+		},
+		"status": {
+			containerHeader: "STATUS",
+			container:       func(container podman.Container) string { return formatStatus(container) },
+		},
+		"uptime": {
+			containerHeader: "UPTIME",
+			container:       func(container podman.Container) string { return formatUptime(container, time.Now()) },
+		},
+	}
+
+	listColumnsDefault = []string{"id", "name", "created", "status", "image"}
+
 	listFlags struct {
+		all            bool
+		columns        string
+		format         string
+		limit          int
+		newerThan      time.Duration
+		noHeader       bool
+		offset         int
 		onlyContainers bool
 		onlyImages     bool
-	}
-
-	// toolboxLabels holds labels used by containers/images that mark them as compatible with Toolbox
-	toolboxLabels = map[string]string{
-		"com.github.debarshiray.toolbox": "true",
-		"com.github.containers.toolbox":  "true",
+		reverse        bool
+		running        bool
+		showLabels     bool
+		sort           string
+		tree           bool
+		watch          bool
 	}
 )
 
@@ -63,18 +150,96 @@ var listCmd = &cobra.Command{
 func init() {
 	flags := listCmd.Flags()
 
+	flags.BoolVarP(&listFlags.all,
+		"all",
+		"a",
+		false,
+		"List all containers and images, not only toolbox ones")
+
+	flags.StringVar(&listFlags.columns,
+		"columns",
+		strings.Join(listColumnsDefault, ","),
+		"Comma-separated columns of the tables, in order")
+
 	flags.BoolVarP(&listFlags.onlyContainers,
 		"containers",
 		"c",
 		false,
 		"List only toolbox containers, not images")
 
+	flags.StringVar(&listFlags.format,
+		"format",
+		"table",
+		"Format the output: csv, json, table, yaml or a Go template")
+
+	flags.IntVar(&listFlags.limit,
+		"limit",
+		0,
+		"List at most this many containers and images, or all if 0")
+
+	flags.DurationVar(&listFlags.newerThan,
+		"newer-than",
+		0,
+		"List only containers and images created within this duration")
+
+	flags.BoolVar(&listFlags.noHeader,
+		"no-header",
+		false,
+		"Don't show the header rows of the tables")
+
+	flags.IntVar(&listFlags.offset,
+		"offset",
+		0,
+		"Skip this many containers and images before listing")
+
 	flags.BoolVarP(&listFlags.onlyImages,
 		"images",
 		"i",
 		false,
 		"List only toolbox images, not containers")
 
+	flags.BoolVar(&listFlags.reverse,
+		"reverse",
+		false,
+		"Reverse the sort order")
+
+	flags.BoolVarP(&listFlags.running,
+		"running",
+		"r",
+		false,
+		"List only running toolbox containers")
+
+	flags.BoolVar(&listFlags.showLabels,
+		"show-labels",
+		false,
+		"Show the labels of containers and images")
+
+	flags.StringVar(&listFlags.sort,
+		"sort",
+		"names",
+		"Sort by the given field: created or names")
+
+	flags.BoolVar(&listFlags.tree,
+		"tree",
+		false,
+		"List toolbox images grouped by the layers that they share")
+
+	flags.BoolVarP(&listFlags.watch,
+		"watch",
+		"w",
+		false,
+		"Keep listing as containers and images change, until interrupted")
+
+	if err := listCmd.RegisterFlagCompletionFunc("format", completionListFormats); err != nil {
+		panicMsg := fmt.Sprintf("failed to register flag completion function: %v", err)
+		panic(panicMsg)
+	}
+
+	if err := listCmd.RegisterFlagCompletionFunc("sort", completionListSortFields); err != nil {
+		panicMsg := fmt.Sprintf("failed to register flag completion function: %v", err)
+		panic(panicMsg)
+	}
+
 	listCmd.SetHelpFunc(listHelp)
 	rootCmd.AddCommand(listCmd)
 }
@@ -92,6 +257,201 @@ func list(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	var listTemplate *template.Template
+
+	if isListTemplate(listFlags.format) {
+		var err error
+		listTemplate, err = parseListTemplate(listFlags.format)
+		if err != nil {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for '--format'\n")
+			fmt.Fprintf(&builder, "%s\n", err)
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return errors.New(errMsg)
+		}
+	} else if listFlags.format != "csv" &&
+		listFlags.format != "json" &&
+		listFlags.format != "table" &&
+		listFlags.format != "yaml" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--format'\n")
+		fmt.Fprintf(&builder, "Supported values are: csv, json, table, yaml, or a Go template\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.tree && listFlags.format != "table" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "options --format and --tree cannot be used together\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.tree && listFlags.onlyContainers {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "options --containers and --tree cannot be used together\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.watch && listFlags.format != "table" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "options --format and --watch cannot be used together\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.watch && listFlags.tree {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "options --tree and --watch cannot be used together\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.sort != "created" && listFlags.sort != "names" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--sort'\n")
+		fmt.Fprintf(&builder, "Supported values are: created, names\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.limit < 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--limit'\n")
+		fmt.Fprintf(&builder, "Must not be negative\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.newerThan < 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--newer-than'\n")
+		fmt.Fprintf(&builder, "Must not be negative\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if listFlags.offset < 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--offset'\n")
+		fmt.Fprintf(&builder, "Must not be negative\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	columns, err := getListColumns(listFlags.columns, listFlags.showLabels)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+
+	if err := podman.PingBackend(ctx); err != nil {
+		return err
+	}
+
+	if err := podman.EnsureSupportedBackend(); err != nil {
+		return err
+	}
+
+	if listFlags.tree {
+		graph, err := podman.GetImageLayerGraph()
+		if err != nil {
+			logrus.Debugf("Getting the layers of images failed: %s", err)
+			return errors.New("failed to get the layers of images")
+		}
+
+		listOutputTree(os.Stdout, graph)
+		return nil
+	}
+
+	needsAllFields := listFlags.format == "json" || listFlags.format == "yaml" || listTemplate != nil
+
+	if listFlags.watch {
+		if err := listWatch(ctx, columns); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	images, containers, err := getListItems(ctx, columns, needsAllFields)
+	if err != nil {
+		return err
+	}
+
+	if listFlags.format == "json" || listFlags.format == "yaml" {
+		document := getListDocument(images, containers, listFlags.offset, listFlags.limit)
+
+		if listFlags.format == "json" {
+			err = listOutputJSON(os.Stdout, document)
+		} else {
+			err = listOutputYAML(os.Stdout, document)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	imagesStart, imagesEnd := getPage(len(images), listFlags.offset, listFlags.limit)
+	images = images[imagesStart:imagesEnd]
+
+	containersStart, containersEnd := getPage(len(containers), listFlags.offset, listFlags.limit)
+	containers = containers[containersStart:containersEnd]
+
+	if listFlags.format == "csv" {
+		if err := listOutputCSV(os.Stdout, columns, images, containers); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if listTemplate != nil {
+		if err := listOutputTemplate(os.Stdout, listTemplate, images, containers); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	stdoutFd := os.Stdout.Fd()
+	stdoutFdInt := int(stdoutFd)
+	isTerminal := term.IsTerminal(stdoutFdInt)
+
+	listOutput(os.Stdout, isTerminal, listFlags.noHeader, columns, images, containers)
+	return nil
+}
+
+// getListItems gets the images and containers selected by the options,
+// sorted, and with the platforms of images and missing images of containers
+// filled in if needsAllFields is true or if the columns show them.
+func getListItems(ctx context.Context, columns []string, needsAllFields bool) ([]podman.Image, []podman.Container, error) {
 	lsContainers := true
 	lsImages := true
 
@@ -101,63 +461,301 @@ func list(cmd *cobra.Command, args []string) error {
 		lsImages = false
 	}
 
+	findMissingImages := lsContainers && (needsAllFields || hasColumn(columns, "image"))
+
+	var allImages []podman.Image
 	var images []podman.Image
-	var containers []toolboxContainer
+	var containers []podman.Container
 	var err error
 
-	if lsImages {
-		images, err = getImages(false)
+	if lsImages || findMissingImages {
+		logrus.Debug("Fetching all images")
+		allImages, err = containerEngine.ListImages(ctx)
 		if err != nil {
-			return err
+			logrus.Debugf("Fetching all images failed: %s", err)
+			return nil, nil, errors.New("failed to get images")
 		}
 	}
 
+	if lsImages {
+		images = filterImages(allImages, false, listFlags.all)
+	}
+
 	if lsContainers {
-		containers, err = getContainers()
+		if listFlags.running {
+			containers, err = getRunningContainers(ctx, listFlags.all)
+		} else {
+			containers, err = getContainers(ctx, listFlags.all)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(images) != 0 && (needsAllFields || hasColumn(columns, "platform")) {
+		if err := podman.GetImagePlatforms(images); err != nil {
+			logrus.Debugf("Getting the platforms of images failed: %s", err)
+		}
+	}
+
+	if len(containers) != 0 && findMissingImages {
+		podman.FindMissingImages(containers, allImages)
+	}
+
+	if listFlags.newerThan != 0 {
+		cutoff := time.Now().Add(-listFlags.newerThan)
+		images = filterImagesNewerThan(images, cutoff)
+		containers = filterContainersNewerThan(containers, cutoff)
+	}
+
+	sortImages(images, listFlags.sort, listFlags.reverse)
+	sortContainers(containers, listFlags.sort, listFlags.reverse)
+
+	return images, containers, nil
+}
+
+// listWatch lists the images and containers as tables, and lists them again
+// whenever Podman reports that a container or an image changed, until
+// toolbox is interrupted. The tables are also refreshed every
+// listWatchRefreshInterval, so that columns like 'uptime' stay current.
+func listWatch(ctx context.Context, columns []string) error {
+	changes := make(chan struct{}, 1)
+	watchErr := make(chan error, 1)
+
+	go func() {
+		watchErr <- podman.WatchEvents(ctx, func(event podman.Event) {
+			logrus.Debugf("Received %s event for %s %s", event.Status, event.Type, event.ID)
+
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	ticker := time.NewTicker(listWatchRefreshInterval)
+	defer ticker.Stop()
+
+	stdoutFd := os.Stdout.Fd()
+	stdoutFdInt := int(stdoutFd)
+	isTerminal := term.IsTerminal(stdoutFdInt)
+
+	for listed := false; ; listed = true {
+		images, containers, err := getListItems(ctx, columns, false)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
 			return err
 		}
+
+		imagesStart, imagesEnd := getPage(len(images), listFlags.offset, listFlags.limit)
+		images = images[imagesStart:imagesEnd]
+
+		containersStart, containersEnd := getPage(len(containers), listFlags.offset, listFlags.limit)
+		containers = containers[containersStart:containersEnd]
+
+		var out bytes.Buffer
+		listOutput(&out, isTerminal, listFlags.noHeader, columns, images, containers)
+
+		if isTerminal {
+			// Move the cursor to the top left corner and clear the
+			// screen, so that the new tables replace the old ones.
+			fmt.Fprintf(os.Stdout, "\033[H\033[2J")
+		} else if listed {
+			fmt.Fprintf(os.Stdout, "\n")
+		}
+
+		out.WriteTo(os.Stdout)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watchErr:
+			if err != nil {
+				logrus.Debugf("Watching for events failed: %s", err)
+				return errors.New("failed to watch for changes to containers and images")
+			}
+
+			return nil
+		case <-ticker.C:
+		case <-changes:
+			// Changes come in bursts, like when a container is
+			// created and started, so wait for the rest of them.
+			time.Sleep(listWatchDelay)
+
+			select {
+			case <-changes:
+			default:
+			}
+		}
 	}
+}
 
-	listOutput(images, containers)
-	return nil
+// filterContainersNewerThan returns the containers created after cutoff.
+// Containers with an unknown creation time are left out.
+func filterContainersNewerThan(containers []podman.Container, cutoff time.Time) []podman.Container {
+	var filtered []podman.Container
+
+	for _, container := range containers {
+		if !container.CreatedAt.IsZero() && container.CreatedAt.After(cutoff) {
+			filtered = append(filtered, container)
+		}
+	}
+
+	return filtered
+}
+
+// filterImagesNewerThan returns the images created after cutoff. Images with
+// an unknown creation time are left out.
+func filterImagesNewerThan(images []podman.Image, cutoff time.Time) []podman.Image {
+	var filtered []podman.Image
+
+	for _, image := range images {
+		if !image.CreatedAt.IsZero() && image.CreatedAt.After(cutoff) {
+			filtered = append(filtered, image)
+		}
+	}
+
+	return filtered
+}
+
+// getListColumns parses the value of '--columns'. The 'labels' column is
+// appended if showLabels is true and it wasn't asked for explicitly.
+func getListColumns(value string, showLabels bool) ([]string, error) {
+	var columns []string
+	hasLabels := false
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := listColumns[name]; !ok {
+			supported := make([]string, 0, len(listColumns))
+			for column := range listColumns {
+				supported = append(supported, column)
+			}
+
+			sort.Strings(supported)
+
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for '--columns'\n")
+			fmt.Fprintf(&builder, "Column %s is not supported\n", name)
+			fmt.Fprintf(&builder, "Supported values are: %s\n", strings.Join(supported, ", "))
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return nil, errors.New(errMsg)
+		}
+
+		if name == "labels" {
+			hasLabels = true
+		}
+
+		columns = append(columns, name)
+	}
+
+	if showLabels && !hasLabels {
+		columns = append(columns, "labels")
+	}
+
+	return columns, nil
 }
 
-func getContainers() ([]toolboxContainer, error) {
+func hasColumn(columns []string, column string) bool {
+	for _, name := range columns {
+		if name == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getContainers returns the toolbox containers, or all containers if all is
+// true.
+func getContainers(ctx context.Context, all bool) ([]podman.Container, error) {
 	logrus.Debug("Fetching all containers")
-	args := []string{"--all", "--sort", "names"}
-	containers, err := podman.GetContainers(args...)
+	containers, err := containerEngine.ListContainers(ctx)
 	if err != nil {
 		logrus.Debugf("Fetching all containers failed: %s", err)
 		return nil, errors.New("failed to get containers")
 	}
 
-	var toolboxContainers []toolboxContainer
+	toolboxContainers := filterContainers(containers, all)
+	return toolboxContainers, nil
+}
+
+// getRunningContainers returns the toolbox containers that are running, or all
+// running containers if all is true.
+func getRunningContainers(ctx context.Context, all bool) ([]podman.Container, error) {
+	logrus.Debug("Fetching running containers")
+	containers, err := containerEngine.ListContainers(ctx)
+	if err != nil {
+		logrus.Debugf("Fetching running containers failed: %s", err)
+		return nil, errors.New("failed to get containers")
+	}
 
+	var runningContainers []podman.Container
 	for _, container := range containers {
-		var c toolboxContainer
+		if container.Status == "running" {
+			runningContainers = append(runningContainers, container)
+		}
+	}
 
-		containerJSON, err := json.Marshal(container)
-		if err != nil {
-			logrus.Errorf("failed to marshal container: %v", err)
+	toolboxContainers := filterContainers(runningContainers, all)
+	return toolboxContainers, nil
+}
+
+func filterContainers(containers []podman.Container, all bool) []podman.Container {
+	var toolboxContainers []podman.Container
+
+	for _, container := range containers {
+		if !all && !container.IsToolbox() {
 			continue
 		}
 
-		err = c.UnmarshalJSON(containerJSON)
-		if err != nil {
-			logrus.Errorf("failed to unmarshal container: %v", err)
+		// Like FlattenNames does for images, a container without any
+		// names is named after its ID, because the name is used to
+		// show, sort and look up containers.
+		if len(container.Names) == 0 {
+			logrus.Debugf("Container %s has no names", container.ID)
+			container.Names = []string{utils.ShortID(container.ID)}
+		}
+
+		toolboxContainers = append(toolboxContainers, container)
+	}
+
+	toolboxContainers = deduplicateContainers(toolboxContainers)
+	return toolboxContainers
+}
+
+// deduplicateContainers drops containers whose name was already seen, because
+// names are used to look up containers and are expected to be unique. Among
+// containers with the same name, the one with the lowest ID is kept,
+// irrespective of the order in which they were listed.
+func deduplicateContainers(containers []podman.Container) []podman.Container {
+	var deduplicated []podman.Container
+	indexes := make(map[string]int)
+
+	for _, container := range containers {
+		name := container.Names[0]
+
+		index, ok := indexes[name]
+		if !ok {
+			indexes[name] = len(deduplicated)
+			deduplicated = append(deduplicated, container)
 			continue
 		}
 
-		for label := range toolboxLabels {
-			if _, ok := c.Labels[label]; ok {
-				toolboxContainers = append(toolboxContainers, c)
-				break
-			}
+		logrus.Warnf("Found more than one container named %s", name)
+
+		if container.ID < deduplicated[index].ID {
+			deduplicated[index] = container
 		}
 	}
 
-	return toolboxContainers, nil
+	return deduplicated
 }
 
 func listHelp(cmd *cobra.Command, args []string) {
@@ -181,15 +779,21 @@ func listHelp(cmd *cobra.Command, args []string) {
 	}
 }
 
-func getImages(fillNameWithID bool) ([]podman.Image, error) {
+// getImages returns the toolbox images, or all images if all is true,
+// flattened so that each has exactly one name.
+func getImages(ctx context.Context, fillNameWithID, all bool) ([]podman.Image, error) {
 	logrus.Debug("Fetching all images")
-	var args []string
-	images, err := podman.GetImages(args...)
+	images, err := containerEngine.ListImages(ctx)
 	if err != nil {
 		logrus.Debugf("Fetching all images failed: %s", err)
 		return nil, errors.New("failed to get images")
 	}
 
+	toolboxImages := filterImages(images, fillNameWithID, all)
+	return toolboxImages, nil
+}
+
+func filterImages(images []podman.Image, fillNameWithID, all bool) []podman.Image {
 	processed := make(map[string]struct{})
 	var toolboxImages []podman.Image
 
@@ -199,101 +803,284 @@ func getImages(fillNameWithID bool) ([]podman.Image, error) {
 		}
 
 		processed[image.ID] = struct{}{}
-		var isToolboxImage bool
+		if all || image.IsToolbox() {
+			flattenedImages := image.FlattenNames(fillNameWithID, false)
+			toolboxImages = append(toolboxImages, flattenedImages...)
+		}
 
-		for label := range toolboxLabels {
-			if _, ok := image.Labels[label]; ok {
-				isToolboxImage = true
-				break
-			}
+	}
+
+	sort.Sort(podman.ImageSlice(toolboxImages))
+	return toolboxImages
+}
+
+// lessByCreated orders newer objects before older ones, or the other way
+// round if reverse is true. Objects with an unknown creation time always come
+// last. The names are used to break ties, so that the order is deterministic.
+func lessByCreated(createdI, createdJ time.Time, nameI, nameJ string, reverse bool) bool {
+	if createdI.IsZero() != createdJ.IsZero() {
+		return createdJ.IsZero()
+	}
+
+	if !createdI.Equal(createdJ) {
+		if reverse {
+			return createdI.Before(createdJ)
 		}
 
-		if isToolboxImage {
-			flattenedImages := image.FlattenNames(fillNameWithID)
-			toolboxImages = append(toolboxImages, flattenedImages...)
+		return createdI.After(createdJ)
+	}
+
+	return nameI < nameJ
+}
+
+func sortContainers(containers []podman.Container, field string, reverse bool) {
+	sort.SliceStable(containers, func(i, j int) bool {
+		nameI := containers[i].Names[0]
+		nameJ := containers[j].Names[0]
+
+		if field == "created" {
+			return lessByCreated(containers[i].CreatedAt, containers[j].CreatedAt, nameI, nameJ, reverse)
 		}
 
+		if reverse {
+			return nameI > nameJ
+		}
+
+		return nameI < nameJ
+	})
+}
+
+func sortImages(images []podman.Image, field string, reverse bool) {
+	if field == "created" {
+		sort.SliceStable(images, func(i, j int) bool {
+			nameI := images[i].Names[0]
+			nameJ := images[j].Names[0]
+			return lessByCreated(images[i].CreatedAt, images[j].CreatedAt, nameI, nameJ, reverse)
+		})
+	} else if reverse {
+		sort.Stable(sort.Reverse(podman.ImageSlice(images)))
+	} else {
+		sort.Stable(podman.ImageSlice(images))
 	}
+}
 
-	sort.Sort(podman.ImageSlice(toolboxImages))
-	return toolboxImages, nil
+// formatCommand joins the command and its arguments, and truncates the result
+// so that it doesn't dominate the table, or returns a dash if it's empty.
+func formatCommand(command []string) string {
+	const maxLength = 30
+
+	if len(command) == 0 {
+		return "-"
+	}
+
+	joined := strings.Join(command, " ")
+	if runes := []rune(joined); len(runes) > maxLength {
+		joined = string(runes[:maxLength-3]) + "..."
+	}
+
+	return joined
+}
+
+// formatCreated returns how long ago something was created. Podman V1 only
+// reported that as a human-readable string, which is used if the time is
+// unknown.
+func formatCreated(createdAt time.Time, created string) string {
+	if createdAt.IsZero() && created != "" {
+		return created
+	}
+
+	return utils.FormatAge(createdAt)
+}
+
+// formatContainerImage returns the image of a container, marked as missing if
+// it doesn't exist anymore.
+func formatContainerImage(container podman.Container) string {
+	if container.ImageMissing {
+		return container.Image + " (image missing)"
+	}
+
+	return container.Image
+}
+
+// formatContainerName returns the name of a container. With '--all', which
+// lists other containers too, it's marked if it's not a toolbox container.
+func formatContainerName(container podman.Container) string {
+	if listFlags.all && !container.IsToolbox() {
+		return container.Names[0] + " (not toolbox)"
+	}
+
+	return container.Names[0]
+}
+
+// formatImageName returns the name of an image, marked like in
+// formatContainerName if it's not a toolbox image.
+func formatImageName(image podman.Image) string {
+	if listFlags.all && !image.IsToolbox() {
+		return image.Names[0] + " (not toolbox)"
+	}
+
+	return image.Names[0]
+}
+
+// formatStatus returns the status of a container, with the exit code if it
+// exited with a non-zero one.
+func formatStatus(container podman.Container) string {
+	if container.Status == "exited" && container.ExitCode != 0 {
+		return fmt.Sprintf("exited (%d)", container.ExitCode)
+	}
+
+	return container.Status
+}
+
+// formatUptime returns how long a running container has been running, or "-"
+// if it isn't running or its start time is unknown.
+func formatUptime(container podman.Container, now time.Time) string {
+	if container.Status != "running" || container.StartedAt.IsZero() {
+		return "-"
+	}
+
+	return utils.FormatDuration(now.Sub(container.StartedAt))
+}
+
+// formatPlatform returns the platform of an image, or "-" if it is unknown or
+// the image is a manifest list.
+func formatPlatform(platform string) string {
+	if platform == "" {
+		return "-"
+	}
+
+	return platform
+}
+
+// formatLabels joins the labels as comma-separated key=value pairs sorted by
+// key, or returns a dash if there are none.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+
+	return strings.Join(pairs, ",")
 }
 
-func listOutput(images []podman.Image, containers []toolboxContainer) {
+// listOutput writes the images and containers as tables to out, with the
+// given columns in the given order. Columns that don't apply to a table, like
+// 'status' for images, are left out of it. If isTerminal is true, the rows
+// are colored using escape sequences. If noHeader is true, the tables have no
+// header rows, which is easier to process with tools like awk(1) and cut(1).
+func listOutput(out io.Writer,
+	isTerminal bool,
+	noHeader bool,
+	columns []string,
+	images []podman.Image,
+	containers []podman.Container) {
 	if len(images) != 0 {
-		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", "IMAGE ID", "IMAGE NAME", "CREATED")
+		var imageColumns []listColumn
+		for _, name := range columns {
+			if column := listColumns[name]; column.imageHeader != "" {
+				imageColumns = append(imageColumns, column)
+			}
+		}
+
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+		if !noHeader {
+			headers := make([]string, 0, len(imageColumns))
+			for _, column := range imageColumns {
+				headers = append(headers, column.imageHeader)
+			}
+
+			fmt.Fprintf(writer, "%s\n", strings.Join(headers, "\t"))
+		}
 
 		for _, image := range images {
 			if len(image.Names) != 1 {
 				panic("cannot list unflattened Image")
 			}
 
-			fmt.Fprintf(writer, "%s\t%s\t%s\n",
-				utils.ShortID(image.ID),
-				image.Names[0],
-				image.Created)
+			cells := make([]string, 0, len(imageColumns))
+			for _, column := range imageColumns {
+				cells = append(cells, column.image(image))
+			}
+
+			fmt.Fprintf(writer, "%s\n", strings.Join(cells, "\t"))
 		}
 
 		writer.Flush()
 	}
 
 	if len(images) != 0 && len(containers) != 0 {
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 
 	if len(containers) != 0 {
 		const boldGreenColor = "\033[1;32m"
+		const boldYellowColor = "\033[1;33m"
 		const defaultColor = "\033[0;00m" // identical to resetColor, but same length as boldGreenColor
 		const resetColor = "\033[0m"
 
-		stdoutFd := os.Stdout.Fd()
-		stdoutFdInt := int(stdoutFd)
-		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-
-		if term.IsTerminal(stdoutFdInt) {
-			fmt.Fprintf(writer, "%s", defaultColor)
+		var containerColumns []listColumn
+		for _, name := range columns {
+			if column := listColumns[name]; column.containerHeader != "" {
+				containerColumns = append(containerColumns, column)
+			}
 		}
 
-		fmt.Fprintf(writer,
-			"%s\t%s\t%s\t%s\t%s",
-			"CONTAINER ID",
-			"CONTAINER NAME",
-			"CREATED",
-			"STATUS",
-			"IMAGE NAME")
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 
-		if term.IsTerminal(stdoutFdInt) {
-			fmt.Fprintf(writer, "%s", resetColor)
-		}
+		if !noHeader {
+			if isTerminal {
+				fmt.Fprintf(writer, "%s", defaultColor)
+			}
+
+			headers := make([]string, 0, len(containerColumns))
+			for _, column := range containerColumns {
+				headers = append(headers, column.containerHeader)
+			}
 
-		fmt.Fprintf(writer, "\n")
+			fmt.Fprintf(writer, "%s", strings.Join(headers, "\t"))
 
-		for _, container := range containers {
-			isRunning := false
-			if podman.CheckVersion("2.0.0") {
-				isRunning = container.Status == "running"
+			if isTerminal {
+				fmt.Fprintf(writer, "%s", resetColor)
 			}
 
-			if term.IsTerminal(stdoutFdInt) {
+			fmt.Fprintf(writer, "\n")
+		}
+
+		for _, container := range containers {
+			if isTerminal {
 				var color string
-				if isRunning {
+				switch container.Status {
+				case "running":
 					color = boldGreenColor
-				} else {
+				case "paused":
+					color = boldYellowColor
+				default:
 					color = defaultColor
 				}
 
 				fmt.Fprintf(writer, "%s", color)
 			}
 
-			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s",
-				utils.ShortID(container.ID),
-				container.Names[0],
-				container.Created,
-				container.Status,
-				container.Image)
+			cells := make([]string, 0, len(containerColumns))
+			for _, column := range containerColumns {
+				cells = append(cells, column.container(container))
+			}
+
+			fmt.Fprintf(writer, "%s", strings.Join(cells, "\t"))
 
-			if term.IsTerminal(stdoutFdInt) {
+			if isTerminal {
 				fmt.Fprintf(writer, "%s", resetColor)
 			}
 
@@ -304,58 +1091,330 @@ func listOutput(images []podman.Image, containers []toolboxContainer) {
 	}
 }
 
-func (c *toolboxContainer) UnmarshalJSON(data []byte) error {
-	var raw struct {
-		ID      string
-		Names   interface{}
-		Status  string
-		State   interface{}
-		Created interface{}
-		Image   string
-		Labels  map[string]string
+// imageTreeGroup is a group of images that share layers, either with each
+// other directly, or through other images in the group.
+type imageTreeGroup struct {
+	images       []string
+	sharedLayers int
+}
+
+// getImageTreeGroups splits the images in a layer graph, as returned by
+// podman.GetImageLayerGraph, into groups of images that share layers, and
+// the standalone images that don't share any. Everything is sorted by name.
+func getImageTreeGroups(graph map[string][]string) ([]imageTreeGroup, []string) {
+	parents := make(map[string]string)
+
+	var find func(name string) string
+	find = func(name string) string {
+		parent, ok := parents[name]
+		if !ok || parent == name {
+			parents[name] = name
+			return name
+		}
+
+		root := find(parent)
+		parents[name] = root
+		return root
 	}
 
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return err
+	for _, names := range graph {
+		for _, name := range names[1:] {
+			parents[find(name)] = find(names[0])
+		}
+
+		find(names[0])
+	}
+
+	members := make(map[string][]string)
+	for name := range parents {
+		root := find(name)
+		members[root] = append(members[root], name)
+	}
+
+	sharedLayers := make(map[string]int)
+	for _, names := range graph {
+		if len(names) > 1 {
+			sharedLayers[find(names[0])]++
+		}
+	}
+
+	var groups []imageTreeGroup
+	var standalone []string
+
+	for root, names := range members {
+		if len(names) == 1 {
+			standalone = append(standalone, names[0])
+			continue
+		}
+
+		sort.Strings(names)
+		groups = append(groups, imageTreeGroup{images: names, sharedLayers: sharedLayers[root]})
 	}
 
-	c.ID = raw.ID
-	// In Podman V1 the field 'Names' held a single string but since Podman V2 the
-	// field holds an array of strings
-	switch value := raw.Names.(type) {
-	case string:
-		c.Names = append(c.Names, value)
-	case []interface{}:
-		for _, v := range value {
-			c.Names = append(c.Names, v.(string))
-		}
-	}
-
-	// In Podman V1 the field holding a string about the container's state was
-	// called 'Status' and field 'State' held a number representing the state. In
-	// Podman V2 the string was moved to 'State' and field 'Status' was dropped.
-	switch value := raw.State.(type) {
-	case string:
-		c.Status = value
-	case float64:
-		c.Status = raw.Status
-	}
-
-	// In Podman V1 the field 'Created' held a human-readable string in format
-	// "5 minutes ago". Since Podman V2 the field holds an integer with Unix time.
-	// After a discussion in https://github.com/containers/podman/issues/6594 the
-	// previous value was moved to field 'CreatedAt'. Since we're already using
-	// the 'github.com/docker/go-units' library, we'll stop using the provided
-	// human-readable string and assemble it ourselves. Go interprets numbers in
-	// JSON as float64.
-	switch value := raw.Created.(type) {
-	case string:
-		c.Created = value
-	case float64:
-		c.Created = utils.HumanDuration(int64(value))
-	}
-	c.Image = raw.Image
-	c.Labels = raw.Labels
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].images[0] < groups[j].images[0]
+	})
+
+	sort.Strings(standalone)
+	return groups, standalone
+}
+
+// listOutputTree writes the images in a layer graph to out as trees, one for
+// each group of images that share layers, followed by the images that don't
+// share any.
+func listOutputTree(out io.Writer, graph map[string][]string) {
+	groups, standalone := getImageTreeGroups(graph)
+
+	writeTree := func(title string, names []string) {
+		fmt.Fprintf(out, "%s\n", title)
+
+		for i, name := range names {
+			branch := "├──"
+			if i == len(names)-1 {
+				branch = "└──"
+			}
+
+			fmt.Fprintf(out, "%s %s\n", branch, name)
+		}
+	}
+
+	for i, group := range groups {
+		if i != 0 {
+			fmt.Fprintln(out)
+		}
+
+		layers := "layers"
+		if group.sharedLayers == 1 {
+			layers = "layer"
+		}
+
+		writeTree(fmt.Sprintf("Sharing %d %s:", group.sharedLayers, layers), group.images)
+	}
+
+	if len(standalone) != 0 {
+		if len(groups) != 0 {
+			fmt.Fprintln(out)
+		}
+
+		writeTree("No shared layers:", standalone)
+	}
+}
+
+// listOutputCSV writes the images and containers to out as a single CSV
+// table with the given columns, preceded by a TYPE column that tells images
+// and containers apart. Cells of columns that don't apply to an image or a
+// container, like 'status' for images, are left empty.
+func listOutputCSV(out io.Writer, columns []string, images []podman.Image, containers []podman.Container) error {
+	writer := csv.NewWriter(out)
+
+	headers := make([]string, 0, len(columns)+1)
+	headers = append(headers, "TYPE")
+	for _, name := range columns {
+		headers = append(headers, strings.ToUpper(name))
+	}
+
+	writer.Write(headers)
+
+	for _, image := range images {
+		if len(image.Names) != 1 {
+			panic("cannot list unflattened Image")
+		}
+
+		record := make([]string, 0, len(columns)+1)
+		record = append(record, "image")
+		for _, name := range columns {
+			var cell string
+			if column := listColumns[name]; column.image != nil {
+				cell = column.image(image)
+			}
+
+			record = append(record, cell)
+		}
+
+		writer.Write(record)
+	}
+
+	for _, container := range containers {
+		record := make([]string, 0, len(columns)+1)
+		record = append(record, "container")
+		for _, name := range columns {
+			var cell string
+			if column := listColumns[name]; column.container != nil {
+				cell = column.container(container)
+			}
+
+			record = append(record, cell)
+		}
+
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logrus.Debugf("Writing the list as CSV failed: %s", err)
+		return errors.New("failed to format the list as CSV")
+	}
+
+	return nil
+}
+
+// isListTemplate checks if the value of '--format' is a Go template instead of
+// the name of a format.
+func isListTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// parseListTemplate parses the Go template given to '--format'. Besides the
+// built-in functions, it can use 'json' to format a value as JSON, and 'join'
+// to join a list of strings, like the names of an image, with a separator.
+func parseListTemplate(format string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"join": strings.Join,
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+
+			return string(data), nil
+		},
+	}
+
+	return template.New("format").Funcs(funcs).Parse(format)
+}
+
+// listOutputTemplate executes the template once for every image, with the
+// podman.Image as its data, and then once for every container, with the
+// podman.Container. Each result is written to out on a line of its own.
+func listOutputTemplate(out io.Writer,
+	listTemplate *template.Template,
+	images []podman.Image,
+	containers []podman.Container) error {
+	var data []interface{}
+
+	for _, image := range images {
+		if len(image.Names) != 1 {
+			panic("cannot list unflattened Image")
+		}
+
+		data = append(data, image)
+	}
+
+	for _, container := range containers {
+		data = append(data, container)
+	}
+
+	for _, item := range data {
+		var builder strings.Builder
+		if err := listTemplate.Execute(&builder, item); err != nil {
+			logrus.Debugf("Executing the template failed: %s", err)
+			return fmt.Errorf("failed to format the list with the template: %w", err)
+		}
+
+		fmt.Fprintf(out, "%s\n", builder.String())
+	}
+
+	return nil
+}
+
+// getListDocument assembles the listDocument for the page of images and
+// containers selected by offset and limit, as explained for getPage.
+func getListDocument(images []podman.Image, containers []podman.Container, offset, limit int) listDocument {
+	document := listDocument{
+		Images:         []podman.Image{},
+		Containers:     []podman.Container{},
+		ImageCount:     len(images),
+		ContainerCount: len(containers),
+	}
+
+	for _, container := range containers {
+		if container.Status == "running" {
+			document.RunningCount++
+		}
+	}
+
+	imagesStart, imagesEnd := getPage(len(images), offset, limit)
+	images = images[imagesStart:imagesEnd]
+
+	containersStart, containersEnd := getPage(len(containers), offset, limit)
+	containers = containers[containersStart:containersEnd]
+
+	// Labels and commands are always emitted as objects and arrays, even
+	// if they are empty, so that consumers don't have to deal with null.
+	for _, image := range images {
+		if image.Labels == nil {
+			image.Labels = map[string]string{}
+		}
+
+		document.Images = append(document.Images, image)
+	}
+
+	for _, container := range containers {
+		if container.Command == nil {
+			container.Command = []string{}
+		}
+
+		if container.Labels == nil {
+			container.Labels = map[string]string{}
+		}
+
+		document.Containers = append(document.Containers, container)
+	}
+
+	return document
+}
+
+// getPage returns the bounds of the page of a list of the given length that
+// skips offset elements and has at most limit elements, or all the remaining
+// ones if limit is 0. The page is empty if offset is past the end.
+func getPage(length, offset, limit int) (int, int) {
+	start := offset
+	if start > length {
+		start = length
+	}
+
+	end := length
+	if limit != 0 && start+limit < end {
+		end = start + limit
+	}
+
+	return start, end
+}
+
+// listOutputJSON writes the document to out as JSON.
+func listOutputJSON(out io.Writer, document listDocument) error {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		logrus.Debugf("Marshalling the list to JSON failed: %s", err)
+		return errors.New("failed to format the list as JSON")
+	}
+
+	fmt.Fprintf(out, "%s\n", data)
+	return nil
+}
+
+// listOutputYAML writes the document to out as YAML. It goes through JSON
+// first, so that it has the same keys in the same order as with
+// listOutputJSON.
+func listOutputYAML(out io.Writer, document listDocument) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		logrus.Debugf("Marshalling the list to JSON failed: %s", err)
+		return errors.New("failed to format the list as YAML")
+	}
+
+	var documentYAML yaml.MapSlice
+	if err := yaml.Unmarshal(data, &documentYAML); err != nil {
+		logrus.Debugf("Unmarshalling the list from JSON failed: %s", err)
+		return errors.New("failed to format the list as YAML")
+	}
+
+	data, err = yaml.Marshal(documentYAML)
+	if err != nil {
+		logrus.Debugf("Marshalling the list to YAML failed: %s", err)
+		return errors.New("failed to format the list as YAML")
+	}
 
+	fmt.Fprintf(out, "%s", data)
 	return nil
 }
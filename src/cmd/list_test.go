@@ -0,0 +1,1249 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/toolbox/pkg/engine"
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSortByCreated(t *testing.T) {
+	older := time.Unix(1600000000, 0)
+	newer := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name     string
+		reverse  bool
+		expected []string
+	}{
+		{
+			name:     "newest first",
+			reverse:  false,
+			expected: []string{"newer", "older", "unknown-a", "unknown-b"},
+		},
+		{
+			name:     "oldest first",
+			reverse:  true,
+			expected: []string{"older", "newer", "unknown-a", "unknown-b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			containers := []podman.Container{
+				{Names: []string{"unknown-b"}},
+				{Names: []string{"older"}, CreatedAt: older},
+				{Names: []string{"unknown-a"}},
+				{Names: []string{"newer"}, CreatedAt: newer},
+			}
+
+			sortContainers(containers, "created", tc.reverse)
+
+			var containerNames []string
+			for _, container := range containers {
+				containerNames = append(containerNames, container.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, containerNames)
+
+			images := []podman.Image{
+				{Names: []string{"unknown-b"}},
+				{Names: []string{"older"}, CreatedAt: older},
+				{Names: []string{"unknown-a"}},
+				{Names: []string{"newer"}, CreatedAt: newer},
+			}
+
+			sortImages(images, "created", tc.reverse)
+
+			var imageNames []string
+			for _, image := range images {
+				imageNames = append(imageNames, image.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, imageNames)
+		})
+	}
+}
+
+func TestListOutput(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:      "8b9affd1dbc261a7f586ed06a8fd993d09449a5ac79ebc7e80e86efdf3c223f6",
+			Names:   []string{"registry.fedoraproject.org/fedora-toolbox:36"},
+			Created: "2 weeks ago",
+		},
+	}
+
+	containers := []podman.Container{
+		{
+			ID:      "4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01",
+			Names:   []string{"fedora-toolbox-36"},
+			Status:  "running",
+			Created: "5 minutes ago",
+			Image:   "registry.fedoraproject.org/fedora-toolbox:36",
+		},
+		{
+			ID:      "d5e0b4e3c6b1f2b3a8c7d9e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6",
+			Names:   []string{"gegl"},
+			Status:  "exited",
+			Created: "3 days ago",
+			Image:   "localhost/gegl:latest",
+		},
+	}
+
+	t.Run("not a terminal", func(t *testing.T) {
+		var out strings.Builder
+		listOutput(&out, false, false, listColumnsDefault, images, containers)
+
+		expected := "" +
+			"IMAGE ID      IMAGE NAME                                    CREATED\n" +
+			"8b9affd1dbc2  registry.fedoraproject.org/fedora-toolbox:36  2 weeks ago\n" +
+			"\n" +
+			"CONTAINER ID  CONTAINER NAME     CREATED        STATUS   IMAGE NAME\n" +
+			"4c9f5ce5f4d4  fedora-toolbox-36  5 minutes ago  running  registry.fedoraproject.org/fedora-toolbox:36\n" +
+			"d5e0b4e3c6b1  gegl               3 days ago     exited   localhost/gegl:latest\n"
+
+		assert.Equal(t, expected, out.String())
+	})
+
+	t.Run("terminal", func(t *testing.T) {
+		var out strings.Builder
+		listOutput(&out, true, false, listColumnsDefault, nil, containers[:1])
+
+		lines := strings.Split(out.String(), "\n")
+		assert.True(t, strings.HasPrefix(lines[0], "\033[0;00mCONTAINER ID"))
+		assert.True(t, strings.HasPrefix(lines[1], "\033[1;32m4c9f5ce5f4d4"))
+		assert.True(t, strings.HasSuffix(lines[1], "\033[0m"))
+	})
+
+	t.Run("terminal, paused", func(t *testing.T) {
+		paused := []podman.Container{containers[0], containers[1]}
+		paused[0].Status = "paused"
+
+		var out strings.Builder
+		listOutput(&out, true, false, listColumnsDefault, nil, paused)
+
+		lines := strings.Split(out.String(), "\n")
+		assert.True(t, strings.HasPrefix(lines[1], "\033[1;33m4c9f5ce5f4d4"))
+		assert.Contains(t, lines[1], "paused")
+		assert.True(t, strings.HasPrefix(lines[2], "\033[0;00md5e0b4e3c6b1"))
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		var out strings.Builder
+		listOutput(&out, false, true, listColumnsDefault, images, containers)
+
+		expected := "" +
+			"8b9affd1dbc2  registry.fedoraproject.org/fedora-toolbox:36  2 weeks ago\n" +
+			"\n" +
+			"4c9f5ce5f4d4  fedora-toolbox-36  5 minutes ago  running  registry.fedoraproject.org/fedora-toolbox:36\n" +
+			"d5e0b4e3c6b1  gegl               3 days ago     exited   localhost/gegl:latest\n"
+
+		assert.Equal(t, expected, out.String())
+		assert.NotContains(t, out.String(), "CONTAINER ID")
+	})
+
+	t.Run("no header, terminal", func(t *testing.T) {
+		var out strings.Builder
+		listOutput(&out, true, true, listColumnsDefault, nil, containers[:1])
+
+		lines := strings.Split(out.String(), "\n")
+		assert.True(t, strings.HasPrefix(lines[0], "\033[1;32m4c9f5ce5f4d4"))
+	})
+
+	t.Run("nothing to list", func(t *testing.T) {
+		var out strings.Builder
+		listOutput(&out, false, false, listColumnsDefault, nil, nil)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		labelledImages := []podman.Image{images[0]}
+		labelledImages[0].Labels = map[string]string{
+			"com.github.containers.toolbox": "true",
+			"version":                       "36",
+		}
+
+		var out strings.Builder
+		columns := []string{"id", "name", "created", "status", "image", "labels"}
+		listOutput(&out, false, false, columns, labelledImages, containers[1:])
+
+		expected := "" +
+			"IMAGE ID      IMAGE NAME                                    CREATED      LABELS\n" +
+			"8b9affd1dbc2  registry.fedoraproject.org/fedora-toolbox:36  2 weeks ago  com.github.containers.toolbox=true,version=36\n" +
+			"\n" +
+			"CONTAINER ID  CONTAINER NAME  CREATED     STATUS  IMAGE NAME             LABELS\n" +
+			"d5e0b4e3c6b1  gegl            3 days ago  exited  localhost/gegl:latest  -\n"
+
+		assert.Equal(t, expected, out.String())
+	})
+}
+
+func TestListOutputColumns(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:      "8b9affd1dbc261a7f586ed06a8fd993d09449a5ac79ebc7e80e86efdf3c223f6",
+			Names:   []string{"registry.fedoraproject.org/fedora-toolbox:36"},
+			Created: "2 weeks ago",
+		},
+	}
+
+	containers := []podman.Container{
+		{
+			ID:      "4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01",
+			Names:   []string{"fedora-toolbox-36"},
+			Status:  "running",
+			Created: "5 minutes ago",
+			Image:   "registry.fedoraproject.org/fedora-toolbox:36",
+		},
+	}
+
+	columns, err := getListColumns("name,status,image,created", false)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	listOutput(&out, false, false, columns, images, containers)
+
+	expected := "" +
+		"IMAGE NAME                                    CREATED\n" +
+		"registry.fedoraproject.org/fedora-toolbox:36  2 weeks ago\n" +
+		"\n" +
+		"CONTAINER NAME     STATUS   IMAGE NAME                                    CREATED\n" +
+		"fedora-toolbox-36  running  registry.fedoraproject.org/fedora-toolbox:36  5 minutes ago\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestListOutputPlatform(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:       "8b9affd1dbc2",
+			Names:    []string{"fedora-toolbox:36"},
+			Platform: "linux/arm64/v8",
+		},
+		{
+			ID:    "f0e1d2c3b4a5",
+			Names: []string{"fedora-toolbox-multi-arch:36"},
+		},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Status: "running"},
+	}
+
+	columns, err := getListColumns("name,platform", false)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	listOutput(&out, false, false, columns, images, containers)
+
+	expected := "" +
+		"IMAGE NAME                    PLATFORM\n" +
+		"fedora-toolbox:36             linux/arm64/v8\n" +
+		"fedora-toolbox-multi-arch:36  -\n" +
+		"\n" +
+		"CONTAINER NAME\n" +
+		"fedora-toolbox-36\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestListOutputCommand(t *testing.T) {
+	containers := []podman.Container{
+		{
+			ID:    "4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01",
+			Names: []string{"fedora-toolbox-36"},
+			Command: []string{
+				"toolbox", "--log-level", "debug", "init-container", "--home", "/home/user",
+			},
+		},
+		{
+			ID:      "d5e0b4e3c6b1f2b3a8c7d9e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6",
+			Names:   []string{"gegl"},
+			Command: []string{"bash", "-l"},
+		},
+		{
+			ID:    "e6f1c5f4d7c2f2b3a8c7d9e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6",
+			Names: []string{"never-started"},
+		},
+	}
+
+	var out strings.Builder
+	listOutput(&out, false, false, []string{"name", "command"}, nil, containers)
+
+	expected := "" +
+		"CONTAINER NAME     COMMAND\n" +
+		"fedora-toolbox-36  toolbox --log-level debug i...\n" +
+		"gegl               bash -l\n" +
+		"never-started      -\n"
+
+	assert.Equal(t, expected, out.String())
+
+	out.Reset()
+	err := listOutputJSON(&out, getListDocument(nil, containers[:1], 0, 0))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "\"/home/user\"")
+}
+
+func TestFormatStatus(t *testing.T) {
+	testCases := []struct {
+		name      string
+		container podman.Container
+		expected  string
+	}{
+		{
+			name:      "running",
+			container: podman.Container{Status: "running"},
+			expected:  "running",
+		},
+		{
+			name:      "exited successfully",
+			container: podman.Container{Status: "exited"},
+			expected:  "exited",
+		},
+		{
+			name:      "exited with an error",
+			container: podman.Container{Status: "exited", ExitCode: 137},
+			expected:  "exited (137)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatStatus(tc.container))
+		})
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		container podman.Container
+		expected  string
+	}{
+		{
+			name: "running",
+			container: podman.Container{
+				Status:    "running",
+				StartedAt: now.Add(-3 * time.Hour),
+			},
+			expected: "3 hours",
+		},
+		{
+			name: "running without start time",
+			container: podman.Container{
+				Status: "running",
+			},
+			expected: "-",
+		},
+		{
+			name: "stopped",
+			container: podman.Container{
+				Status:    "exited",
+				StartedAt: now.Add(-3 * time.Hour),
+			},
+			expected: "-",
+		},
+		{
+			name: "created",
+			container: podman.Container{
+				Status: "created",
+			},
+			expected: "-",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatUptime(tc.container, now))
+		})
+	}
+}
+
+func TestGetListItemsMissingImage(t *testing.T) {
+	defaultEngine := containerEngine
+	t.Cleanup(func() {
+		containerEngine = defaultEngine
+	})
+
+	toolboxLabels := map[string]string{"com.github.containers.toolbox": "true"}
+
+	containerEngine = fakeEngine{
+		containers: []podman.Container{
+			{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, ImageID: "8b9affd1dbc2", Labels: toolboxLabels},
+			{ID: "d5e0b4e3c6b1", Names: []string{"fedora-toolbox-35"}, ImageID: "a0c1b1ff3de4", Labels: toolboxLabels},
+			{ID: "e6f1c5f4d7c2", Names: []string{"gegl"}, ImageID: "d5e0b4e3c6b1", Labels: toolboxLabels},
+		},
+		images: []podman.Image{
+			{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:36"}, Labels: toolboxLabels},
+			{ID: "d5e0b4e3c6b1"},
+		},
+	}
+
+	shelltest.SetUp(t, podman.SetRunner, shelltest.Exit(1))
+
+	columns, err := getListColumns("name,image", false)
+	require.NoError(t, err)
+
+	_, containers, err := getListItems(context.Background(), columns, false)
+	require.NoError(t, err)
+	require.Len(t, containers, 3)
+
+	missing := make(map[string]bool)
+	for _, container := range containers {
+		missing[container.Names[0]] = container.ImageMissing
+	}
+
+	assert.Equal(t, map[string]bool{"fedora-toolbox-36": false, "fedora-toolbox-35": true, "gegl": false}, missing)
+}
+
+func TestListOutputMissingImage(t *testing.T) {
+	containers := []podman.Container{
+		{
+			ID:     "4c9f5ce5f4d4",
+			Names:  []string{"fedora-toolbox-36"},
+			Status: "running",
+			Image:  "registry.fedoraproject.org/fedora-toolbox:36",
+		},
+		{
+			ID:           "d5e0b4e3c6b1",
+			Names:        []string{"fedora-toolbox-35"},
+			Status:       "exited",
+			Image:        "registry.fedoraproject.org/fedora-toolbox:35",
+			ImageMissing: true,
+		},
+	}
+
+	columns, err := getListColumns("name,image", false)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	listOutput(&out, false, false, columns, nil, containers)
+
+	expected := "" +
+		"CONTAINER NAME     IMAGE NAME\n" +
+		"fedora-toolbox-36  registry.fedoraproject.org/fedora-toolbox:36\n" +
+		"fedora-toolbox-35  registry.fedoraproject.org/fedora-toolbox:35 (image missing)\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestListOutputUptime(t *testing.T) {
+	containers := []podman.Container{
+		{
+			ID:        "4c9f5ce5f4d4",
+			Names:     []string{"fedora-toolbox-36"},
+			Status:    "running",
+			StartedAt: time.Now().Add(-45 * time.Minute),
+		},
+		{
+			ID:        "d5e0b4e3c6b1",
+			Names:     []string{"gegl"},
+			Status:    "exited",
+			StartedAt: time.Now().Add(-2 * time.Hour),
+		},
+	}
+
+	columns, err := getListColumns("name,uptime", false)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	listOutput(&out, false, false, columns, nil, containers)
+
+	expected := "" +
+		"CONTAINER NAME     UPTIME\n" +
+		"fedora-toolbox-36  45 minutes\n" +
+		"gegl               -\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestGetListColumns(t *testing.T) {
+	testCases := []struct {
+		name       string
+		value      string
+		showLabels bool
+		expected   []string
+		errMsg     string
+	}{
+		{
+			name:     "default",
+			value:    "id,name,created,status,image",
+			expected: []string{"id", "name", "created", "status", "image"},
+		},
+		{
+			name:     "custom order",
+			value:    "name, status,id",
+			expected: []string{"name", "status", "id"},
+		},
+		{
+			name:       "show labels",
+			value:      "name",
+			showLabels: true,
+			expected:   []string{"name", "labels"},
+		},
+		{
+			name:       "show labels in the given position",
+			value:      "labels,name",
+			showLabels: true,
+			expected:   []string{"labels", "name"},
+		},
+		{
+			name:  "unknown column",
+			value: "name,size",
+			errMsg: "invalid argument for '--columns'\n" +
+				"Column size is not supported\n" +
+				"Supported values are: command, created, id, image, labels, name, platform, status, uptime\n" +
+				"Run '" + executableBase + " --help' for usage.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			columns, err := getListColumns(tc.value, tc.showLabels)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, columns)
+		})
+	}
+}
+
+func TestListOutputJSON(t *testing.T) {
+	images := []podman.Image{
+		{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:36"}},
+		{ID: "9c0b0ee2ecd3", Names: []string{"fedora-toolbox:37"}},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Status: "running"},
+		{ID: "d5e0b4e3c6b1", Names: []string{"fedora-toolbox-37"}, Status: "exited"},
+		{ID: "e6f1c5f4d7c2", Names: []string{"gegl"}, Status: "running"},
+	}
+
+	var out strings.Builder
+	err := listOutputJSON(&out, getListDocument(images, containers, 0, 0))
+	assert.NoError(t, err)
+
+	var document struct {
+		Images         []interface{} `json:"images"`
+		Containers     []interface{} `json:"containers"`
+		ImageCount     int           `json:"imageCount"`
+		ContainerCount int           `json:"containerCount"`
+		RunningCount   int           `json:"runningCount"`
+	}
+
+	err = json.Unmarshal([]byte(out.String()), &document)
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(document.Images), document.ImageCount)
+	assert.Equal(t, 2, document.ImageCount)
+	assert.Equal(t, len(document.Containers), document.ContainerCount)
+	assert.Equal(t, 3, document.ContainerCount)
+	assert.Equal(t, 2, document.RunningCount)
+}
+
+func TestListOutputJSONLabels(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:     "8b9affd1dbc2",
+			Names:  []string{"fedora-toolbox:36"},
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}},
+	}
+
+	var out strings.Builder
+	err := listOutputJSON(&out, getListDocument(images, containers, 0, 0))
+	assert.NoError(t, err)
+
+	var document struct {
+		Images []struct {
+			Labels map[string]string
+		} `json:"images"`
+		Containers []struct {
+			Labels map[string]string
+		} `json:"containers"`
+	}
+
+	err = json.Unmarshal([]byte(out.String()), &document)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"com.github.containers.toolbox": "true"}, document.Images[0].Labels)
+	assert.NotNil(t, document.Containers[0].Labels)
+	assert.Empty(t, document.Containers[0].Labels)
+	assert.NotContains(t, out.String(), "null")
+}
+
+func TestDeduplicateContainers(t *testing.T) {
+	hook := logrustest.NewGlobal()
+	defer hook.Reset()
+
+	containers := []podman.Container{
+		{ID: "e6f1c5f4d7c2", Names: []string{"fedora-toolbox-36"}},
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}},
+		{ID: "d5e0b4e3c6b1", Names: []string{"gegl"}},
+	}
+
+	deduplicated := deduplicateContainers(containers)
+
+	assert.Len(t, deduplicated, 2)
+	assert.Equal(t, "4c9f5ce5f4d4", deduplicated[0].ID)
+	assert.Equal(t, "d5e0b4e3c6b1", deduplicated[1].ID)
+
+	var out strings.Builder
+	listOutput(&out, false, false, listColumnsDefault, nil, deduplicated)
+	assert.Equal(t, 1, strings.Count(out.String(), "fedora-toolbox-36"))
+
+	assert.Len(t, hook.Entries, 1)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Found more than one container named fedora-toolbox-36", hook.LastEntry().Message)
+}
+
+func TestFilterContainersWithoutNames(t *testing.T) {
+	containers := []podman.Container{
+		{ID: "e6f1c5f4d7c2a8b9", Names: []string{"fedora-toolbox-36"}},
+		{ID: "4c9f5ce5f4d4a8b9"},
+		{ID: "d5e0b4e3c6b1a8b9", Names: []string{}},
+	}
+
+	filtered := filterContainers(containers, true)
+	require.Len(t, filtered, 3)
+
+	sortContainers(filtered, "name", false)
+
+	var names []string
+	for _, container := range filtered {
+		names = append(names, container.Names[0])
+	}
+
+	assert.Equal(t, []string{"4c9f5ce5f4d4", "d5e0b4e3c6b1", "fedora-toolbox-36"}, names)
+}
+
+func TestFilterImages(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:     "8b9affd1dbc2",
+			Names:  []string{"registry.fedoraproject.org/fedora-toolbox:36"},
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+		{
+			ID:     "9c0b0ee2ecd3",
+			Names:  []string{"localhost/legacy-toolbox:latest"},
+			Labels: map[string]string{"com.github.debarshiray.toolbox": "true"},
+		},
+		{
+			ID:     "e6f1c5f4d7c2",
+			Names:  []string{"registry.fedoraproject.org/fedora:36"},
+			Labels: map[string]string{"license": "MIT"},
+		},
+		{
+			ID:     "a7f2d6e5c8b3",
+			Names:  []string{"localhost/not-a-toolbox:latest"},
+			Labels: map[string]string{"com.github.containers.toolbox": "false"},
+		},
+		{
+			ID:    "d5e0b4e3c6b1",
+			Names: []string{"docker.io/library/alpine:latest"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		all      bool
+		expected []string
+	}{
+		{
+			name: "toolbox images",
+			expected: []string{
+				"localhost/legacy-toolbox:latest",
+				"registry.fedoraproject.org/fedora-toolbox:36",
+			},
+		},
+		{
+			name: "all images",
+			all:  true,
+			expected: []string{
+				"docker.io/library/alpine:latest",
+				"localhost/legacy-toolbox:latest",
+				"localhost/not-a-toolbox:latest",
+				"registry.fedoraproject.org/fedora-toolbox:36",
+				"registry.fedoraproject.org/fedora:36",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered := filterImages(images, false, tc.all)
+
+			var names []string
+			for _, image := range filtered {
+				names = append(names, image.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestListOutputYAML(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:     "8b9affd1dbc2",
+			Names:  []string{"fedora-toolbox:36"},
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Status: "running"},
+		{ID: "d5e0b4e3c6b1", Names: []string{"gegl"}, Status: "exited"},
+	}
+
+	var out strings.Builder
+	err := listOutputYAML(&out, getListDocument(images, containers, 0, 0))
+	assert.NoError(t, err)
+
+	var document yaml.MapSlice
+	err = yaml.Unmarshal([]byte(out.String()), &document)
+	require.NoError(t, err)
+
+	var keys []interface{}
+	for _, item := range document {
+		keys = append(keys, item.Key)
+	}
+
+	assert.Equal(t, []interface{}{"images", "containers", "imageCount", "containerCount", "runningCount"}, keys)
+
+	var counts struct {
+		ImageCount     int `yaml:"imageCount"`
+		ContainerCount int `yaml:"containerCount"`
+		RunningCount   int `yaml:"runningCount"`
+	}
+
+	err = yaml.Unmarshal([]byte(out.String()), &counts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, counts.ImageCount)
+	assert.Equal(t, 2, counts.ContainerCount)
+	assert.Equal(t, 1, counts.RunningCount)
+	assert.Contains(t, out.String(), "com.github.containers.toolbox: \"true\"")
+}
+
+func TestListOutputCSV(t *testing.T) {
+	images := []podman.Image{
+		{
+			ID:      "8b9affd1dbc2",
+			Names:   []string{"fedora-toolbox:36"},
+			Created: "2 weeks ago",
+		},
+	}
+
+	containers := []podman.Container{
+		{
+			ID:      "4c9f5ce5f4d4",
+			Names:   []string{"fedora-toolbox-36"},
+			Created: "5 days ago",
+			Status:  "running",
+			Image:   "registry.fedoraproject.org/fedora-toolbox:36",
+			Labels:  map[string]string{"com.github.containers.toolbox": "true", "project": "gegl, babl"},
+		},
+	}
+
+	var out strings.Builder
+	err := listOutputCSV(&out, []string{"id", "name", "status", "labels"}, images, containers)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	require.NoError(t, err)
+
+	expected := [][]string{
+		{"TYPE", "ID", "NAME", "STATUS", "LABELS"},
+		{"image", "8b9affd1dbc2", "fedora-toolbox:36", "", "-"},
+		{
+			"container",
+			"4c9f5ce5f4d4",
+			"fedora-toolbox-36",
+			"running",
+			"com.github.containers.toolbox=true,project=gegl, babl",
+		},
+	}
+
+	assert.Equal(t, expected, records)
+	assert.Contains(t, out.String(), "\"com.github.containers.toolbox=true,project=gegl, babl\"")
+}
+
+func TestListOutputTree(t *testing.T) {
+	testCases := []struct {
+		name     string
+		graph    map[string][]string
+		expected string
+	}{
+		{
+			name: "shared base",
+			graph: map[string][]string{
+				"sha256:base":    {"fedora-toolbox-gegl:36", "fedora-toolbox:36"},
+				"sha256:toolbox": {"fedora-toolbox-gegl:36", "fedora-toolbox:36"},
+				"sha256:gegl":    {"fedora-toolbox-gegl:36"},
+				"sha256:alpine":  {"alpine-toolbox:latest"},
+			},
+			expected: "" +
+				"Sharing 2 layers:\n" +
+				"├── fedora-toolbox-gegl:36\n" +
+				"└── fedora-toolbox:36\n" +
+				"\n" +
+				"No shared layers:\n" +
+				"└── alpine-toolbox:latest\n",
+		},
+		{
+			name: "shared through another image",
+			graph: map[string][]string{
+				"sha256:base":  {"fedora-toolbox-gegl:36", "fedora-toolbox:36"},
+				"sha256:gegl":  {"fedora-toolbox-gegl:36", "fedora-toolbox-gimp:36"},
+				"sha256:babl":  {"ubuntu-toolbox:22.04", "ubuntu-toolbox-babl:22.04"},
+				"sha256:other": {"ubuntu-toolbox:22.04"},
+			},
+			expected: "" +
+				"Sharing 2 layers:\n" +
+				"├── fedora-toolbox-gegl:36\n" +
+				"├── fedora-toolbox-gimp:36\n" +
+				"└── fedora-toolbox:36\n" +
+				"\n" +
+				"Sharing 1 layer:\n" +
+				"├── ubuntu-toolbox-babl:22.04\n" +
+				"└── ubuntu-toolbox:22.04\n",
+		},
+		{
+			name: "nothing shared",
+			graph: map[string][]string{
+				"sha256:base":   {"fedora-toolbox:36"},
+				"sha256:alpine": {"alpine-toolbox:latest"},
+			},
+			expected: "" +
+				"No shared layers:\n" +
+				"├── alpine-toolbox:latest\n" +
+				"└── fedora-toolbox:36\n",
+		},
+		{
+			name:     "no images",
+			graph:    map[string][]string{},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			listOutputTree(&out, tc.graph)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestGetPage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		offset int
+		limit  int
+		start  int
+		end    int
+	}{
+		{name: "everything", start: 0, end: 5},
+		{name: "limit", limit: 2, start: 0, end: 2},
+		{name: "offset", offset: 3, start: 3, end: 5},
+		{name: "offset and limit", offset: 1, limit: 2, start: 1, end: 3},
+		{name: "limit past the end", offset: 4, limit: 2, start: 4, end: 5},
+		{name: "offset at the end", offset: 5, start: 5, end: 5},
+		{name: "offset past the end", offset: 7, limit: 2, start: 5, end: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := getPage(5, tc.offset, tc.limit)
+			assert.Equal(t, tc.start, start)
+			assert.Equal(t, tc.end, end)
+		})
+	}
+}
+
+func TestGetListDocumentPage(t *testing.T) {
+	images := []podman.Image{
+		{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:36"}},
+		{ID: "9c0b0ee2ecd3", Names: []string{"fedora-toolbox:37"}},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Status: "running"},
+		{ID: "d5e0b4e3c6b1", Names: []string{"fedora-toolbox-37"}, Status: "exited"},
+		{ID: "e6f1c5f4d7c2", Names: []string{"gegl"}, Status: "running"},
+	}
+
+	document := getListDocument(images, containers, 1, 1)
+	assert.Len(t, document.Images, 1)
+	assert.Equal(t, "9c0b0ee2ecd3", document.Images[0].ID)
+	assert.Len(t, document.Containers, 1)
+	assert.Equal(t, "d5e0b4e3c6b1", document.Containers[0].ID)
+	assert.Equal(t, 2, document.ImageCount)
+	assert.Equal(t, 3, document.ContainerCount)
+	assert.Equal(t, 2, document.RunningCount)
+
+	document = getListDocument(images, containers, 10, 0)
+	assert.Empty(t, document.Images)
+	assert.NotNil(t, document.Images)
+	assert.Empty(t, document.Containers)
+	assert.Equal(t, 2, document.ImageCount)
+	assert.Equal(t, 3, document.ContainerCount)
+}
+
+func TestGetRunningContainers(t *testing.T) {
+	shelltest.SetUp(t, podman.SetRunner, shelltest.Output(`[
+  {"Id": "4c9f5ce5f4d4", "Names": ["running"], "State": "running", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "5d0a6df6a5e5", "Names": ["running-v1"], "State": 3, "Status": "Up 5 minutes", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "6e1b7e07b6f6", "Names": ["exited"], "State": "exited", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "7f2c8f18c707", "Names": ["paused"], "State": "paused", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "803d9029d818", "Names": ["created"], "State": "created", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "914ea13ae929", "Names": ["not-a-toolbox"], "State": "running", "Labels": null}
+]`))
+
+	containers, err := getRunningContainers(context.Background(), false)
+	require.NoError(t, err)
+
+	var names []string
+	for _, container := range containers {
+		names = append(names, container.Names[0])
+		assert.Equal(t, "running", container.Status)
+	}
+
+	assert.Equal(t, []string{"running", "running-v1"}, names)
+}
+
+func TestFilterNewerThan(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-time.Hour)
+
+	images := []podman.Image{
+		{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:36"}, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "9c0b0ee2ecd3", Names: []string{"fedora-toolbox:37"}, CreatedAt: now.Add(-time.Minute)},
+		{ID: "d5e0b4e3c6b1", Names: []string{"fedora-toolbox:38"}},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, CreatedAt: now.Add(-30 * time.Minute)},
+		{ID: "d5e0b4e3c6b1", Names: []string{"fedora-toolbox-37"}, CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "e6f1c5f4d7c2", Names: []string{"gegl"}},
+	}
+
+	filteredImages := filterImagesNewerThan(images, cutoff)
+	assert.Len(t, filteredImages, 1)
+	assert.Equal(t, "9c0b0ee2ecd3", filteredImages[0].ID)
+
+	filteredContainers := filterContainersNewerThan(containers, cutoff)
+	assert.Len(t, filteredContainers, 1)
+	assert.Equal(t, "4c9f5ce5f4d4", filteredContainers[0].ID)
+}
+
+func TestFilterContainers(t *testing.T) {
+	containers := []podman.Container{
+		{
+			ID:     "4c9f5ce5f4d4",
+			Names:  []string{"fedora-toolbox-36"},
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+		{
+			ID:     "d5e0b4e3c6b1",
+			Names:  []string{"legacy-toolbox"},
+			Labels: map[string]string{"com.github.debarshiray.toolbox": "true"},
+		},
+		{
+			ID:     "e6f1c5f4d7c2",
+			Names:  []string{"web-server"},
+			Labels: map[string]string{"io.podman.compose.project": "web"},
+		},
+		{
+			ID:    "a7f2d6e5c8b3",
+			Names: []string{"unlabelled"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		all      bool
+		expected []string
+	}{
+		{
+			name:     "toolbox containers",
+			expected: []string{"fedora-toolbox-36", "legacy-toolbox"},
+		},
+		{
+			name:     "all containers",
+			all:      true,
+			expected: []string{"fedora-toolbox-36", "legacy-toolbox", "web-server", "unlabelled"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered := filterContainers(containers, tc.all)
+
+			var names []string
+			for _, container := range filtered {
+				names = append(names, container.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+// fakeEngine lists the given containers and images. Its other methods are
+// those of the embedded Engine, and panic if it's nil.
+type fakeEngine struct {
+	engine.Engine
+	containers []podman.Container
+	images     []podman.Image
+}
+
+func (e fakeEngine) ListContainers(ctx context.Context) ([]podman.Container, error) {
+	return e.containers, nil
+}
+
+func (e fakeEngine) ListImages(ctx context.Context) ([]podman.Image, error) {
+	return e.images, nil
+}
+
+func TestGetContainersAndImagesFromEngine(t *testing.T) {
+	defaultEngine := containerEngine
+	t.Cleanup(func() {
+		containerEngine = defaultEngine
+	})
+
+	containerEngine = fakeEngine{
+		containers: []podman.Container{
+			{
+				ID:     "4c9f5ce5f4d4",
+				Names:  []string{"fedora-toolbox-36"},
+				Labels: map[string]string{"com.github.containers.toolbox": "true"},
+			},
+			{
+				ID:    "e6f1c5f4d7c2",
+				Names: []string{"web-server"},
+			},
+		},
+		images: []podman.Image{
+			{
+				ID:     "8b9affd1dbc2",
+				Names:  []string{"fedora-toolbox:36"},
+				Labels: map[string]string{"com.github.containers.toolbox": "true"},
+			},
+			{
+				ID:    "d5e0b4e3c6b1",
+				Names: []string{"alpine:latest"},
+			},
+		},
+	}
+
+	containers, err := getContainers(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	assert.Equal(t, "fedora-toolbox-36", containers[0].Names[0])
+
+	images, err := getImages(context.Background(), false, false)
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "fedora-toolbox:36", images[0].Names[0])
+}
+
+func TestListOutputTemplate(t *testing.T) {
+	images := []podman.Image{
+		{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:36"}},
+	}
+
+	containers := []podman.Container{
+		{
+			ID:     "4c9f5ce5f4d4",
+			Names:  []string{"fedora-toolbox-36"},
+			Status: "running",
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+		{ID: "d5e0b4e3c6b1", Names: []string{"fedora-toolbox-37", "toolbox"}, Status: "exited"},
+	}
+
+	testCases := []struct {
+		name       string
+		format     string
+		images     []podman.Image
+		containers []podman.Container
+		expected   string
+		errMsg     string
+	}{
+		{
+			name:       "names",
+			format:     "{{index .Names 0}}",
+			images:     images,
+			containers: containers,
+			expected:   "fedora-toolbox:36\nfedora-toolbox-36\nfedora-toolbox-37\n",
+		},
+		{
+			name:       "join",
+			format:     "{{.ID}} {{join .Names \",\"}} {{.Status}}",
+			containers: containers,
+			expected:   "4c9f5ce5f4d4 fedora-toolbox-36 running\nd5e0b4e3c6b1 fedora-toolbox-37,toolbox exited\n",
+		},
+		{
+			name:       "json",
+			format:     "{{json .Labels}}",
+			containers: containers[:1],
+			expected:   "{\"com.github.containers.toolbox\":\"true\"}\n",
+		},
+		{
+			name:   "field missing from images",
+			format: "{{.Status}}",
+			images: images,
+			errMsg: "failed to format the list with the template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listTemplate, err := parseListTemplate(tc.format)
+			assert.NoError(t, err)
+
+			var out strings.Builder
+			err = listOutputTemplate(&out, listTemplate, tc.images, tc.containers)
+			if tc.errMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestParseListTemplateInvalid(t *testing.T) {
+	assert.True(t, isListTemplate("{{.ID}}"))
+	assert.False(t, isListTemplate("json"))
+
+	_, err := parseListTemplate("{{.ID")
+	assert.Error(t, err)
+}
+
+func TestListOutputAll(t *testing.T) {
+	listFlags.all = true
+	t.Cleanup(func() {
+		listFlags.all = false
+	})
+
+	images := []podman.Image{
+		{
+			ID:     "8b9affd1dbc2",
+			Names:  []string{"fedora-toolbox:36"},
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+		{ID: "9c0b0ee2ecd3", Names: []string{"registry.fedoraproject.org/fedora:36"}},
+	}
+
+	containers := []podman.Container{
+		{
+			ID:     "4c9f5ce5f4d4",
+			Names:  []string{"fedora-toolbox-36"},
+			Labels: map[string]string{"com.github.containers.toolbox": "true"},
+		},
+		{ID: "d5e0b4e3c6b1", Names: []string{"web-server"}},
+	}
+
+	columns, err := getListColumns("name", false)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	listOutput(&out, false, false, columns, images, containers)
+
+	expected := "" +
+		"IMAGE NAME\n" +
+		"fedora-toolbox:36\n" +
+		"registry.fedoraproject.org/fedora:36 (not toolbox)\n" +
+		"\n" +
+		"CONTAINER NAME\n" +
+		"fedora-toolbox-36\n" +
+		"web-server (not toolbox)\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestListWatchWithIncompatibleOptions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format string
+		tree   bool
+		errMsg string
+	}{
+		{
+			name:   "format",
+			format: "json",
+			errMsg: "options --format and --watch cannot be used together",
+		},
+		{
+			name:   "tree",
+			format: "table",
+			tree:   true,
+			errMsg: "options --tree and --watch cannot be used together",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listFlags.format = tc.format
+			listFlags.tree = tc.tree
+			listFlags.watch = true
+			listFlags.sort = "names"
+			t.Cleanup(func() {
+				listFlags.format = "table"
+				listFlags.tree = false
+				listFlags.watch = false
+			})
+
+			err := list(listCmd, nil)
+
+			expected := tc.errMsg + "\n" + "Run '" + executableBase + " --help' for usage."
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFlags struct {
+		follow bool
+		tail   int
+	}
+)
+
+var logsCmd = &cobra.Command{
+	Use:               "logs",
+	Short:             "Show the output of a toolbox container",
+	RunE:              logs,
+	ValidArgsFunction: completionContainerNamesFiltered,
+}
+
+func init() {
+	flags := logsCmd.Flags()
+
+	flags.BoolVarP(&logsFlags.follow,
+		"follow",
+		"f",
+		false,
+		"Keep showing new output until the container stops")
+
+	flags.IntVar(&logsFlags.tail,
+		"tail",
+		0,
+		"Show only this many of the last lines, or all if 0")
+
+	logsCmd.SetHelpFunc(logsHelp)
+	rootCmd.AddCommand(logsCmd)
+}
+
+func logs(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if len(args) != 1 {
+		var builder strings.Builder
+		if len(args) == 0 {
+			fmt.Fprintf(&builder, "missing argument for \"logs\"\n")
+		} else {
+			fmt.Fprintf(&builder, "too many arguments for \"logs\"\n")
+		}
+
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if logsFlags.tail < 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--tail'\n")
+		fmt.Fprintf(&builder, "Must not be negative\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	container := args[0]
+
+	if _, err := podman.IsToolboxContainer(container); err != nil {
+		return err
+	}
+
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+
+	options := podman.LogsOptions{
+		Follow: logsFlags.follow,
+		Tail:   logsFlags.tail,
+	}
+
+	if err := podman.Logs(ctx, container, options, os.Stdout, os.Stderr); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func logsHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-logs"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogsErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []string
+		tail   int
+		errMsg string
+	}{
+		{
+			name:   "no container",
+			errMsg: "missing argument for \"logs\"\nRun '" + executableBase + " --help' for usage.",
+		},
+		{
+			name:   "two containers",
+			args:   []string{"fedora-toolbox-36", "fedora-toolbox-37"},
+			errMsg: "too many arguments for \"logs\"\nRun '" + executableBase + " --help' for usage.",
+		},
+		{
+			name: "negative tail",
+			args: []string{"fedora-toolbox-36"},
+			tail: -1,
+			errMsg: "invalid argument for '--tail'\nMust not be negative\n" +
+				"Run '" + executableBase + " --help' for usage.",
+		},
+		{
+			name:   "non-toolbox container",
+			args:   []string{"plain-container"},
+			errMsg: "plain-container is not a toolbox container",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, podman.SetRunner, fakeInspectPodman)
+
+			logsFlags.tail = tc.tail
+			t.Cleanup(func() {
+				logsFlags.tail = 0
+			})
+
+			err := logs(logsCmd, tc.args)
+			assert.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
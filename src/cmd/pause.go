@@ -0,0 +1,153 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pauseFlags struct {
+		all bool
+	}
+)
+
+var pauseCmd = &cobra.Command{
+	Use:               "pause",
+	Short:             "Pause one or more running toolbox containers",
+	RunE:              pause,
+	ValidArgsFunction: completionContainerNamesFiltered,
+}
+
+func init() {
+	flags := pauseCmd.Flags()
+
+	flags.BoolVarP(&pauseFlags.all, "all", "a", false, "Pause all running toolbox containers")
+
+	pauseCmd.SetHelpFunc(pauseHelp)
+	rootCmd.AddCommand(pauseCmd)
+}
+
+func pause(cmd *cobra.Command, args []string) error {
+	return pauseOrUnpause("pause", pauseFlags.all, args, podman.PauseAll, podman.Pause)
+}
+
+func pauseHelp(cmd *cobra.Command, args []string) {
+	pauseOrUnpauseHelp("pause", cmd, args)
+}
+
+// pauseOrUnpause implements both 'toolbox pause' and 'toolbox unpause', which
+// only differ in verb and in the functions used to act on all the containers
+// or only on one.
+func pauseOrUnpause(verb string,
+	all bool,
+	args []string,
+	forAll func() ([]string, map[string]error, error),
+	forOne func(container string) error) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if all && len(args) != 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "option --all cannot be used with containers\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if all {
+		_, errs, err := forAll()
+		if err != nil {
+			return err
+		}
+
+		containers := make([]string, 0, len(errs))
+		for container := range errs {
+			containers = append(containers, container)
+		}
+
+		sort.Strings(containers)
+
+		for _, container := range containers {
+			fmt.Fprintf(os.Stderr, "Error: failed to %s container %s: %s\n", verb, container, errs[container])
+		}
+
+		return nil
+	}
+
+	if len(args) == 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "missing argument for \"%s\"\n", verb)
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	for _, container := range args {
+		if _, err := podman.IsToolboxContainer(container); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			continue
+		}
+
+		if err := forOne(container); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to %s container %s: %s\n", verb, container, err)
+			continue
+		}
+	}
+
+	return nil
+}
+
+func pauseOrUnpauseHelp(verb string, cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-" + verb); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
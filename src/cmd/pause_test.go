@@ -0,0 +1,83 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseUnpauseErrors(t *testing.T) {
+	commands := []struct {
+		name string
+		all  *bool
+		run  func(*cobra.Command, []string) error
+		cmd  *cobra.Command
+	}{
+		{
+			name: "pause",
+			all:  &pauseFlags.all,
+			run:  pause,
+			cmd:  pauseCmd,
+		},
+		{
+			name: "unpause",
+			all:  &unpauseFlags.all,
+			run:  unpause,
+			cmd:  unpauseCmd,
+		},
+	}
+
+	for _, command := range commands {
+		t.Run(command.name, func(t *testing.T) {
+			t.Run("no container", func(t *testing.T) {
+				err := command.run(command.cmd, nil)
+				assert.EqualError(t, err,
+					"missing argument for \""+command.name+"\"\n"+
+						"Run '"+executableBase+" --help' for usage.")
+			})
+
+			t.Run("--all with containers", func(t *testing.T) {
+				*command.all = true
+				t.Cleanup(func() {
+					*command.all = false
+				})
+
+				err := command.run(command.cmd, []string{"fedora-toolbox-36"})
+				assert.EqualError(t, err,
+					"option --all cannot be used with containers\n"+
+						"Run '"+executableBase+" --help' for usage.")
+			})
+
+			t.Run("--all without containers", func(t *testing.T) {
+				shelltest.SetUp(t, podman.SetRunner, shelltest.Output("[]"))
+
+				*command.all = true
+				t.Cleanup(func() {
+					*command.all = false
+				})
+
+				err := command.run(command.cmd, nil)
+				assert.NoError(t, err)
+			})
+		})
+	}
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneContainersFlags struct {
+		dryRun bool
+		force  bool
+		until  time.Duration
+	}
+
+	pruneImagesFlags struct {
+		dryRun bool
+		force  bool
+	}
+)
+
+var pruneCmd = &cobra.Command{
+	Use:               "prune",
+	Short:             "Remove unused toolbox containers or images",
+	RunE:              prune,
+	ValidArgsFunction: completionEmpty,
+}
+
+var pruneContainersCmd = &cobra.Command{
+	Use:               "containers",
+	Short:             "Remove toolbox containers that aren't running",
+	RunE:              pruneContainers,
+	ValidArgsFunction: completionEmpty,
+}
+
+var pruneImagesCmd = &cobra.Command{
+	Use:               "images",
+	Short:             "Remove toolbox images that aren't used by any container",
+	RunE:              pruneImages,
+	ValidArgsFunction: completionEmpty,
+}
+
+func init() {
+	flags := pruneContainersCmd.Flags()
+
+	flags.BoolVar(&pruneContainersFlags.dryRun,
+		"dry-run",
+		false,
+		"Only show which toolbox containers would be removed")
+
+	flags.BoolVarP(&pruneContainersFlags.force,
+		"force",
+		"f",
+		false,
+		"Don't ask for confirmation")
+
+	flags.DurationVar(&pruneContainersFlags.until,
+		"until",
+		0,
+		"Remove only containers that stopped at least this long ago")
+
+	flags = pruneImagesCmd.Flags()
+
+	flags.BoolVar(&pruneImagesFlags.dryRun,
+		"dry-run",
+		false,
+		"Only show which toolbox images would be removed")
+
+	flags.BoolVarP(&pruneImagesFlags.force,
+		"force",
+		"f",
+		false,
+		"Don't ask for confirmation")
+
+	pruneCmd.SetHelpFunc(pruneHelp)
+	pruneContainersCmd.SetHelpFunc(pruneHelp)
+	pruneImagesCmd.SetHelpFunc(pruneHelp)
+
+	pruneCmd.AddCommand(pruneContainersCmd)
+	pruneCmd.AddCommand(pruneImagesCmd)
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func prune(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "missing argument for \"prune\"\n")
+	fmt.Fprintf(&builder, "Supported values are: containers, images\n")
+	fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+	errMsg := builder.String()
+	return errors.New(errMsg)
+}
+
+func pruneContainers(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if pruneContainersFlags.until < 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--until'\n")
+		fmt.Fprintf(&builder, "Must not be negative\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	var until time.Time
+	if pruneContainersFlags.until != 0 {
+		until = time.Now().Add(-pruneContainersFlags.until)
+	}
+
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+
+	report, errs, err := podman.PruneContainers(ctx, true, until)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Removed) == 0 {
+		printPruneErrors(errs)
+		return nil
+	}
+
+	pruneOutput(os.Stdout, "CONTAINER NAME", report)
+
+	if pruneContainersFlags.dryRun {
+		printPruneErrors(errs)
+		fmt.Printf("Would reclaim %s\n", formatSize(report.ReclaimedBytes))
+		return nil
+	}
+
+	if !pruneContainersFlags.force &&
+		!confirmRemoval(os.Stdin, os.Stdout, isStdinTerminal(), len(report.Removed), "toolbox containers") {
+		return nil
+	}
+
+	report, errs = podman.RemovePrunedContainers(ctx, report)
+	if len(report.Removed) != 0 {
+		removeCompletionCache("containers")
+	}
+
+	printPruneErrors(errs)
+	fmt.Printf("Reclaimed %s\n", formatSize(report.ReclaimedBytes))
+	return nil
+}
+
+func pruneImages(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+
+	report, errs, err := podman.PruneImages(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Removed) == 0 {
+		printPruneErrors(errs)
+		return nil
+	}
+
+	pruneOutput(os.Stdout, "IMAGE NAME", report)
+
+	if pruneImagesFlags.dryRun {
+		printPruneErrors(errs)
+		fmt.Printf("Would reclaim %s\n", formatSize(report.ReclaimedBytes))
+		return nil
+	}
+
+	if !pruneImagesFlags.force &&
+		!confirmRemoval(os.Stdin, os.Stdout, isStdinTerminal(), len(report.Removed), "toolbox images") {
+		return nil
+	}
+
+	report, errs = podman.RemovePrunedImages(ctx, report)
+	if len(report.Removed) != 0 {
+		removeCompletionCache("images")
+	}
+
+	printPruneErrors(errs)
+	fmt.Printf("Reclaimed %s\n", formatSize(report.ReclaimedBytes))
+	return nil
+}
+
+func pruneHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-prune"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// formatSize returns a size in bytes in a human-readable form, or "-" if it's
+// unknown.
+func formatSize(size int64) string {
+	if size < 0 {
+		return "-"
+	}
+
+	sizeFloat := float64(size)
+	return units.HumanSize(sizeFloat)
+}
+
+// pruneOutput writes a table with the names and sizes of what a prune removed,
+// or would remove, to out.
+func pruneOutput(out io.Writer, header string, report podman.PruneReport) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "%s\tSIZE\n", header)
+
+	for _, name := range report.Removed {
+		size := formatSize(report.Sizes[name])
+		fmt.Fprintf(writer, "%s\t%s\n", name, size)
+	}
+
+	writer.Flush()
+}
+
+// printPruneErrors prints the errors from a prune in the order of the names of
+// the containers or images that they are about.
+func printPruneErrors(errs map[string]error) {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", errs[name])
+	}
+}
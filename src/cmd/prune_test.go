@@ -0,0 +1,70 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneOutput(t *testing.T) {
+	report := podman.PruneReport{
+		Removed: []string{"fedora-toolbox:35", "fedora-toolbox:37"},
+		Sizes: map[string]int64{
+			"fedora-toolbox:35": -1,
+			"fedora-toolbox:37": 912345678,
+		},
+		ReclaimedBytes: 912345678,
+	}
+
+	var out strings.Builder
+	pruneOutput(&out, "IMAGE NAME", report)
+
+	expected := "" +
+		"IMAGE NAME         SIZE\n" +
+		"fedora-toolbox:35  -\n" +
+		"fedora-toolbox:37  912.3MB\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestPruneWithoutSubcommand(t *testing.T) {
+	err := prune(pruneCmd, nil)
+
+	expected := "missing argument for \"prune\"\n" +
+		"Supported values are: containers, images\n" +
+		"Run '" + executableBase + " --help' for usage."
+	assert.EqualError(t, err, expected)
+}
+
+func TestPruneContainersNegativeUntil(t *testing.T) {
+	pruneContainersFlags.until = -time.Hour
+	t.Cleanup(func() {
+		pruneContainersFlags.until = 0
+	})
+
+	err := pruneContainers(pruneContainersCmd, nil)
+
+	expected := "invalid argument for '--until'\n" +
+		"Must not be negative\n" +
+		"Run '" + executableBase + " --help' for usage."
+	assert.EqualError(t, err, expected)
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restartFlags struct {
+		time uint
+	}
+)
+
+var restartCmd = &cobra.Command{
+	Use:               "restart",
+	Short:             "Restart one or more toolbox containers",
+	RunE:              restart,
+	ValidArgsFunction: completionContainerNamesFiltered,
+}
+
+func init() {
+	flags := restartCmd.Flags()
+
+	flags.UintVarP(&restartFlags.time,
+		"time",
+		"t",
+		10,
+		"Seconds to wait for a container to stop before killing it")
+
+	restartCmd.SetHelpFunc(restartHelp)
+	rootCmd.AddCommand(restartCmd)
+}
+
+func restart(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if len(args) == 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "missing argument for \"restart\"\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	for _, container := range args {
+		if err := restartContainer(container); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			continue
+		}
+	}
+
+	return nil
+}
+
+// restartContainer stops the container, if it's running, and starts it again
+// the same way as enter and run do, so that its entry point sets it up anew.
+func restartContainer(container string) error {
+	if _, err := podman.IsToolboxContainer(container); err != nil {
+		return err
+	}
+
+	logrus.Debugf("Stopping container %s", container)
+
+	if err := podman.Stop(container, restartFlags.time); err != nil {
+		return fmt.Errorf("failed to stop container %s: %w", container, err)
+	}
+
+	if err := callFlatpakSessionHelper(container); err != nil {
+		return err
+	}
+
+	logrus.Debugf("Starting container %s", container)
+
+	if err := startContainer(container); err != nil {
+		return err
+	}
+
+	if err := waitForContainerInitialization(container); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func restartHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-restart"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestartContainerErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		container string
+		errMsg    string
+	}{
+		{
+			name:      "non-toolbox container",
+			container: "plain-container",
+			errMsg:    "plain-container is not a toolbox container",
+		},
+		{
+			name:      "stopping fails",
+			container: "fedora-toolbox-36",
+			errMsg: "failed to stop container fedora-toolbox-36: " +
+				"failed to invoke podman(1): given PID did not die within timeout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, podman.SetRunner, func(call shelltest.Call) int {
+				if call.Arg(3) == "stop" {
+					fmt.Fprintln(call.Stderr, "Error: given PID did not die within timeout")
+					return 125
+				}
+
+				return fakeInspectPodman(call)
+			})
+
+			err := restartContainer(tc.container)
+			assert.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
@@ -69,18 +69,43 @@ func rm(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if rmFlags.deleteAll && len(args) != 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "option --all cannot be used with containers\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
 	if rmFlags.deleteAll {
-		toolboxContainers, err := getContainers()
+		ctx, cancel := newInterruptibleContext()
+		defer cancel()
+
+		toolboxContainers, err := getContainers(ctx, false)
 		if err != nil {
 			return err
 		}
 
+		if len(toolboxContainers) == 0 {
+			return nil
+		}
+
+		if !confirmRemoval(os.Stdin, os.Stdout, isStdinTerminal(), len(toolboxContainers), "toolbox containers") {
+			return nil
+		}
+
 		for _, container := range toolboxContainers {
-			containerID := container.ID
-			if err := podman.RemoveContainer(containerID, rmFlags.forceDelete); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			if err := containerEngine.RemoveContainer(container.Names[0], rmFlags.forceDelete); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 				continue
 			}
+
+			removeCompletionCache("containers")
 		}
 	} else {
 		if len(args) == 0 {
@@ -98,10 +123,17 @@ func rm(cmd *cobra.Command, args []string) error {
 				continue
 			}
 
-			if err := podman.RemoveContainer(container, rmFlags.forceDelete); err != nil {
+			if err := containerEngine.RemoveContainer(container, rmFlags.forceDelete); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+
+				if errors.Is(err, podman.ErrContainerRunning) {
+					fmt.Fprintf(os.Stderr, "Use '%s rm --force' to remove a running container.\n", executableBase)
+				}
+
 				continue
 			}
+
+			removeCompletionCache("containers")
 		}
 	}
 
@@ -0,0 +1,88 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/engine"
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRmAllWithContainers(t *testing.T) {
+	rmFlags.deleteAll = true
+	t.Cleanup(func() {
+		rmFlags.deleteAll = false
+	})
+
+	err := rm(rmCmd, []string{"fedora-toolbox-36"})
+
+	expected := "option --all cannot be used with containers\n" +
+		"Run '" + executableBase + " --help' for usage."
+	assert.EqualError(t, err, expected)
+}
+
+func TestRmAllRemovesListedContainers(t *testing.T) {
+	defaultEngine := containerEngine
+	t.Cleanup(func() {
+		containerEngine = defaultEngine
+	})
+
+	toolboxLabels := map[string]string{"com.github.containers.toolbox": "true"}
+
+	containerEngine = fakeEngine{
+		Engine: engine.NewPodman(),
+		containers: []podman.Container{
+			{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Labels: toolboxLabels},
+			{ID: "d5e0b4e3c6b1", Names: []string{"gegl"}, Labels: toolboxLabels},
+		},
+	}
+
+	rmFlags.deleteAll = true
+	rootFlags.assumeYes = true
+	t.Cleanup(func() {
+		rmFlags.deleteAll = false
+		rootFlags.assumeYes = false
+	})
+
+	cachePath := setUpCompletionCache(t, "containers")
+	err := writeCompletionCache(cachePath, []string{"fedora-toolbox-36", "gegl"})
+	require.NoError(t, err)
+
+	r := shelltest.SetUp(t, podman.SetRunner, func(call shelltest.Call) int {
+		if call.Arg(3) == "ps" {
+			fmt.Fprintln(call.Stdout,
+				`[{"Names": ["created-after-confirming"], "Labels": {"com.github.containers.toolbox": "true"}}]`)
+		}
+
+		return 0
+	})
+
+	err = rm(rmCmd, nil)
+	require.NoError(t, err)
+
+	expected := []string{
+		"--log-level error rm fedora-toolbox-36",
+		"--log-level error rm gegl",
+	}
+	assert.Equal(t, expected, r.Calls())
+	assert.NoFileExists(t, cachePath)
+}
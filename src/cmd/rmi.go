@@ -19,11 +19,13 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/containers/toolbox/pkg/podman"
 	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -44,13 +46,13 @@ var rmiCmd = &cobra.Command{
 func init() {
 	flags := rmiCmd.Flags()
 
-	flags.BoolVarP(&rmiFlags.deleteAll, "all", "a", false, "Remove all toolbox containers")
+	flags.BoolVarP(&rmiFlags.deleteAll, "all", "a", false, "Remove all toolbox images")
 
 	flags.BoolVarP(&rmiFlags.forceDelete,
 		"force",
 		"f",
 		false,
-		"Force the removal of running and paused toolbox containers")
+		"Force the removal of toolbox images used by containers, and remove the containers too")
 
 	rmiCmd.SetHelpFunc(rmiHelp)
 	rootCmd.AddCommand(rmiCmd)
@@ -69,15 +71,50 @@ func rmi(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if rmiFlags.deleteAll && len(args) != 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "option --all cannot be used with images\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
 	if rmiFlags.deleteAll {
-		toolboxImages, err := getImages(false)
+		ctx, cancel := newInterruptibleContext()
+		defer cancel()
+
+		toolboxImages, err := getImages(ctx, false, false)
 		if err != nil {
 			return err
 		}
 
+		imageIDs := make(map[string]struct{})
 		for _, image := range toolboxImages {
+			imageIDs[image.ID] = struct{}{}
+		}
+
+		if len(imageIDs) == 0 {
+			return nil
+		}
+
+		if !confirmRemoval(os.Stdin, os.Stdout, isStdinTerminal(), len(imageIDs), "toolbox images") {
+			return nil
+		}
+
+		for _, image := range toolboxImages {
+			if ctx.Err() != nil {
+				break
+			}
+
 			imageID := image.ID
-			if err := podman.RemoveImage(imageID, rmiFlags.forceDelete); err != nil {
+			if _, ok := imageIDs[imageID]; !ok {
+				continue
+			}
+
+			delete(imageIDs, imageID)
+
+			if err := removeImage(os.Stdin, os.Stdout, isStdinTerminal(), imageID); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 				continue
 			}
@@ -98,7 +135,7 @@ func rmi(cmd *cobra.Command, args []string) error {
 				continue
 			}
 
-			if err := podman.RemoveImage(image, rmiFlags.forceDelete); err != nil {
+			if err := removeImage(os.Stdin, os.Stdout, isStdinTerminal(), image); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 				continue
 			}
@@ -108,6 +145,44 @@ func rmi(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// removeImage removes image. With --force, the user is first asked whether
+// the containers created from it should be removed along with it, and nothing
+// is removed if the answer is no.
+func removeImage(in io.Reader, out io.Writer, isTerminal bool, image string) error {
+	if rmiFlags.forceDelete && !confirmRemovalOfDependentContainers(in, out, isTerminal, image) {
+		return nil
+	}
+
+	if err := containerEngine.RemoveImage(image, rmiFlags.forceDelete); err != nil {
+		return err
+	}
+
+	// With --force, containers might have been removed too.
+	removeCompletionCache("containers", "images")
+	return nil
+}
+
+// confirmRemovalOfDependentContainers asks the user whether the containers
+// created from image should be removed along with it, if there are any.
+func confirmRemovalOfDependentContainers(in io.Reader, out io.Writer, isTerminal bool, image string) bool {
+	containers, err := podman.GetContainersUsingImage(image)
+	if err != nil {
+		logrus.Debugf("Getting the containers using image %s failed: %s", image, err)
+		return true
+	}
+
+	if len(containers) == 0 {
+		return true
+	}
+
+	noun := fmt.Sprintf("containers using image %s", image)
+	if len(containers) == 1 {
+		noun = fmt.Sprintf("container using image %s", image)
+	}
+
+	return confirmRemoval(in, out, isTerminal, len(containers), noun)
+}
+
 func rmiHelp(cmd *cobra.Command, args []string) {
 	if utils.IsInsideContainer() {
 		if !utils.IsInsideToolboxContainer() {
@@ -0,0 +1,145 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/engine"
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRmiAllWithImages(t *testing.T) {
+	rmiFlags.deleteAll = true
+	t.Cleanup(func() {
+		rmiFlags.deleteAll = false
+	})
+
+	err := rmi(rmiCmd, []string{"fedora-toolbox:36"})
+
+	expected := "option --all cannot be used with images\n" +
+		"Run '" + executableBase + " --help' for usage."
+	assert.EqualError(t, err, expected)
+}
+
+func TestRmiAllForce(t *testing.T) {
+	defaultEngine := containerEngine
+	t.Cleanup(func() {
+		containerEngine = defaultEngine
+	})
+
+	containerEngine = fakeEngine{
+		Engine: engine.NewPodman(),
+		images: []podman.Image{
+			{
+				ID:     "8b9affd1dbc2",
+				Names:  []string{"fedora-toolbox:36", "fedora-toolbox:latest"},
+				Labels: map[string]string{"com.github.containers.toolbox": "true"},
+			},
+		},
+	}
+
+	rmiFlags.deleteAll = true
+	rmiFlags.forceDelete = true
+	rootFlags.assumeYes = true
+	t.Cleanup(func() {
+		rmiFlags.deleteAll = false
+		rmiFlags.forceDelete = false
+		rootFlags.assumeYes = false
+	})
+
+	r := shelltest.SetUp(t, podman.SetRunner, func(call shelltest.Call) int {
+		if call.Arg(3) == "ps" {
+			fmt.Fprintln(call.Stdout, `[{"Names": ["fedora-toolbox-36"]}]`)
+		}
+
+		return 0
+	})
+
+	err := rmi(rmiCmd, nil)
+	require.NoError(t, err)
+
+	expected := []string{
+		"--log-level error ps --format json --all --filter ancestor=8b9affd1dbc2",
+		"--log-level error rmi --force 8b9affd1dbc2",
+	}
+	assert.Equal(t, expected, r.Calls())
+}
+
+func TestRemoveImageDeclined(t *testing.T) {
+	rmiFlags.forceDelete = true
+	t.Cleanup(func() {
+		rmiFlags.forceDelete = false
+	})
+
+	r := shelltest.SetUp(t, podman.SetRunner, func(call shelltest.Call) int {
+		if call.Arg(3) == "ps" {
+			fmt.Fprintln(call.Stdout, `[{"Names": ["fedora-toolbox-36"]}, {"Names": ["fedora-toolbox-36-gegl"]}]`)
+		}
+
+		return 0
+	})
+
+	var out strings.Builder
+	err := removeImage(strings.NewReader("n\n"), &out, true, "fedora-toolbox:36")
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "Remove 2 containers using image fedora-toolbox:36? [y/N]")
+
+	for _, call := range r.Calls() {
+		assert.NotContains(t, call, " rmi ")
+	}
+}
+
+func TestConfirmRemovalOfDependentContainers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		podman   shelltest.Handler
+		expected bool
+	}{
+		{
+			name:     "no containers",
+			podman:   shelltest.Exit(0),
+			expected: true,
+		},
+		{
+			name:     "listing containers fails",
+			podman:   shelltest.Exit(1),
+			expected: true,
+		},
+		{
+			name:     "containers without a terminal",
+			podman:   shelltest.Output(`[{"Names": ["fedora-toolbox-36"]}]`),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, podman.SetRunner, tc.podman)
+
+			ok := confirmRemovalOfDependentContainers(strings.NewReader(""), ioutil.Discard, false, "fedora-toolbox:36")
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
@@ -26,16 +26,22 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/containers/toolbox/pkg/engine"
 	"github.com/containers/toolbox/pkg/podman"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/containers/toolbox/pkg/version"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
 	cgroupsVersion int
 
+	// containerEngine is what toolbox containers and images are managed
+	// with. It can be changed with the 'engine' option in toolbox.conf.
+	containerEngine engine.Engine = engine.NewPodman()
+
 	currentUser *user.User
 
 	executable string
@@ -169,6 +175,10 @@ func preRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := setUpContainerEngine(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -206,6 +216,25 @@ func rootRun(cmd *cobra.Command, args []string) error {
 	return rootRunImpl(cmd, args)
 }
 
+// setUpContainerEngine selects the container engine named by the 'engine'
+// option in the configuration, if it's set.
+func setUpContainerEngine() error {
+	if !viper.IsSet("general.engine") {
+		return nil
+	}
+
+	name := viper.GetString("general.engine")
+
+	selectedEngine, err := engine.New(name)
+	if err != nil {
+		return err
+	}
+
+	logrus.Debugf("Using container engine %s", name)
+	containerEngine = selectedEngine
+	return nil
+}
+
 func migrate(cmd *cobra.Command, args []string) error {
 	logrus.Debug("Migrating to newer Podman")
 
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,3 +74,21 @@ func TestExitError(t *testing.T) {
 		})
 	}
 }
+
+func TestSetUpContainerEngine(t *testing.T) {
+	defaultEngine := containerEngine
+	t.Cleanup(func() {
+		containerEngine = defaultEngine
+		viper.Set("general.engine", nil)
+	})
+
+	viper.Set("general.engine", "podman")
+	err := setUpContainerEngine()
+	assert.NoError(t, err)
+	assert.Equal(t, "podman", containerEngine.Name())
+
+	viper.Set("general.engine", "docker")
+	err = setUpContainerEngine()
+	assert.EqualError(t, err, "container engine docker is not supported")
+	assert.Equal(t, "podman", containerEngine.Name())
+}
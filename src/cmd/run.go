@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -25,7 +26,6 @@ import (
 	"time"
 
 	"github.com/containers/toolbox/pkg/podman"
-	"github.com/containers/toolbox/pkg/shell"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -186,7 +186,7 @@ func runCommand(container string,
 			return err
 		}
 
-		containers, err := getContainers()
+		containers, err := getContainers(context.Background(), false)
 		if err != nil {
 			err := createErrorContainerNotFound(container)
 			return err
@@ -215,7 +215,7 @@ func runCommand(container string,
 				return nil
 			}
 
-			if err := createContainer(container, image, release, "", false); err != nil {
+			if err := createContainer(container, image, release, podman.PullOptions{}, nil, false); err != nil {
 				return err
 			}
 		} else if containersCount == 1 && defaultContainer {
@@ -245,46 +245,10 @@ func runCommand(container string,
 		return err
 	}
 
-	entryPoint, entryPointPID, err := getEntryPointAndPID(container)
-	if err != nil {
-		return err
-	}
-
-	if entryPoint != "toolbox" {
-		var builder strings.Builder
-		fmt.Fprintf(&builder, "container %s is too old and no longer supported \n", container)
-		fmt.Fprintf(&builder, "Recreate it with Toolbox version 0.0.17 or newer.\n")
-
-		errMsg := builder.String()
-		return errors.New(errMsg)
-	}
-
-	if entryPointPID <= 0 {
-		return fmt.Errorf("invalid entry point PID of container %s", container)
-	}
-
-	logrus.Debugf("Waiting for container %s to finish initializing", container)
-
-	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(currentUser)
-	if err != nil {
+	if err := waitForContainerInitialization(container); err != nil {
 		return err
 	}
 
-	initializedStamp := fmt.Sprintf("%s/container-initialized-%d", toolboxRuntimeDirectory, entryPointPID)
-
-	logrus.Debugf("Checking if initialization stamp %s exists", initializedStamp)
-
-	initializedTimeout := 25 // seconds
-	for i := 0; !utils.PathExists(initializedStamp); i++ {
-		if i == initializedTimeout {
-			return fmt.Errorf("failed to initialize container %s", container)
-		}
-
-		time.Sleep(time.Second)
-	}
-
-	logrus.Debugf("Container %s is initialized", container)
-
 	if err := runCommandWithFallbacks(container,
 		preserveFDs,
 		command,
@@ -349,12 +313,12 @@ func runCommandWithFallbacks(container string,
 		}
 
 		logrus.Debugf("Running in container %s:", container)
-		logrus.Debug("podman")
+		logrus.Debugf("%s exec", containerEngine.Name())
 		for _, arg := range execArgs {
 			logrus.Debugf("%s", arg)
 		}
 
-		exitCode, err := shell.RunWithExitCode("podman", os.Stdin, os.Stdout, stderr, execArgs...)
+		exitCode, err := containerEngine.Exec(execArgs, os.Stdin, os.Stdout, stderr)
 
 		if emitEscapeSequence {
 			fmt.Printf("\033]777;container;pop;;;%s\033\\", currentUser.Uid)
@@ -437,7 +401,7 @@ func runHelp(cmd *cobra.Command, args []string) {
 func callFlatpakSessionHelper(container string) error {
 	logrus.Debugf("Inspecting mounts of container %s", container)
 
-	info, err := podman.Inspect("container", container)
+	info, err := containerEngine.Inspect("container", container)
 	if err != nil {
 		return fmt.Errorf("failed to inspect entry point of container %s", container)
 	}
@@ -485,12 +449,7 @@ func constructExecArgs(container, preserveFDs string,
 	fallbackToBash bool,
 	ttyNeeded bool,
 	workDir string) []string {
-	logLevelString := podman.LogLevel.String()
-
-	execArgs := []string{
-		"--log-level", logLevelString,
-		"exec",
-	}
+	var execArgs []string
 
 	if detachKeysSupported {
 		execArgs = append(execArgs, []string{
@@ -529,7 +488,7 @@ func constructExecArgs(container, preserveFDs string,
 func getEntryPointAndPID(container string) (string, int, error) {
 	logrus.Debugf("Inspecting entry point of container %s", container)
 
-	info, err := podman.Inspect("container", container)
+	info, err := containerEngine.Inspect("container", container)
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to inspect entry point of container %s", container)
 	}
@@ -558,38 +517,42 @@ func getEntryPointAndPID(container string) (string, int, error) {
 func isCommandPresent(container, command string) (bool, error) {
 	logrus.Debugf("Looking up command %s in container %s", command, container)
 
-	logLevelString := podman.LogLevel.String()
 	args := []string{
-		"--log-level", logLevelString,
-		"exec",
 		"--user", currentUser.Username,
 		container,
 		"sh", "-c", "command -v \"$1\"", "sh", command,
 	}
 
-	if err := shell.Run("podman", nil, nil, nil, args...); err != nil {
+	exitCode, err := containerEngine.Exec(args, nil, nil, nil)
+	if err != nil {
 		return false, err
 	}
 
+	if exitCode != 0 {
+		return false, fmt.Errorf("failed to invoke %s(1)", containerEngine.Name())
+	}
+
 	return true, nil
 }
 
 func isPathPresent(container, path string) (bool, error) {
 	logrus.Debugf("Looking up path %s in container %s", path, container)
 
-	logLevelString := podman.LogLevel.String()
 	args := []string{
-		"--log-level", logLevelString,
-		"exec",
 		"--user", currentUser.Username,
 		container,
 		"sh", "-c", "test -d \"$1\"", "sh", path,
 	}
 
-	if err := shell.Run("podman", nil, nil, nil, args...); err != nil {
+	exitCode, err := containerEngine.Exec(args, nil, nil, nil)
+	if err != nil {
 		return false, err
 	}
 
+	if exitCode != 0 {
+		return false, fmt.Errorf("failed to invoke %s(1)", containerEngine.Name())
+	}
+
 	return true, nil
 }
 
@@ -631,3 +594,50 @@ func startContainer(container string) error {
 
 	return nil
 }
+
+// waitForContainerInitialization waits for the entry point of a freshly
+// started container to finish setting it up, which is signalled by a stamp
+// file in the runtime directory.
+func waitForContainerInitialization(container string) error {
+	entryPoint, entryPointPID, err := getEntryPointAndPID(container)
+	if err != nil {
+		return err
+	}
+
+	if entryPoint != "toolbox" {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "container %s is too old and no longer supported \n", container)
+		fmt.Fprintf(&builder, "Recreate it with Toolbox version 0.0.17 or newer.\n")
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	if entryPointPID <= 0 {
+		return fmt.Errorf("invalid entry point PID of container %s", container)
+	}
+
+	logrus.Debugf("Waiting for container %s to finish initializing", container)
+
+	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(currentUser)
+	if err != nil {
+		return err
+	}
+
+	initializedStamp := fmt.Sprintf("%s/container-initialized-%d", toolboxRuntimeDirectory, entryPointPID)
+
+	logrus.Debugf("Checking if initialization stamp %s exists", initializedStamp)
+
+	initializedTimeout := 25 // seconds
+	for i := 0; !utils.PathExists(initializedStamp); i++ {
+		if i == initializedTimeout {
+			return fmt.Errorf("failed to initialize container %s", container)
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	logrus.Debugf("Container %s is initialized", container)
+
+	return nil
+}
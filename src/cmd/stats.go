@@ -0,0 +1,176 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	statsFlags struct {
+		noStream bool
+	}
+)
+
+var statsCmd = &cobra.Command{
+	Use:               "stats",
+	Short:             "Show the resource usage of running toolbox containers",
+	RunE:              stats,
+	ValidArgsFunction: completionContainerNamesFiltered,
+}
+
+func init() {
+	flags := statsCmd.Flags()
+
+	flags.BoolVar(&statsFlags.noStream,
+		"no-stream",
+		false,
+		"Show the resource usage once, instead of until interrupted")
+
+	statsCmd.SetHelpFunc(statsHelp)
+	rootCmd.AddCommand(statsCmd)
+}
+
+func stats(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	containers := args
+
+	for _, container := range containers {
+		if _, err := podman.IsToolboxContainer(container); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+
+	stdoutFd := os.Stdout.Fd()
+	stdoutFdInt := int(stdoutFd)
+	isTerminal := term.IsTerminal(stdoutFdInt)
+
+	// Without arguments, the running containers are looked up every
+	// time, so that those started later show up too.
+	listContainers := func() ([]string, error) {
+		if len(args) != 0 {
+			return containers, nil
+		}
+
+		runningContainers, err := getRunningContainers(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for _, container := range runningContainers {
+			names = append(names, container.Names[0])
+		}
+
+		return names, nil
+	}
+
+	shown := false
+
+	return podman.WatchStats(ctx, listContainers, func(containersStats []podman.Stats) error {
+		var out bytes.Buffer
+		statsOutput(&out, containersStats)
+
+		if statsFlags.noStream {
+			out.WriteTo(os.Stdout)
+			cancel()
+			return nil
+		}
+
+		if isTerminal {
+			// Move the cursor to the top left corner and clear the
+			// screen, so that the new table replaces the old one.
+			fmt.Fprintf(os.Stdout, "\033[H\033[2J")
+		} else if shown {
+			fmt.Fprintf(os.Stdout, "\n")
+		}
+
+		out.WriteTo(os.Stdout)
+		shown = true
+		return nil
+	})
+}
+
+func statsHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-stats"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// statsOutput writes a table with the resource usage of each container to
+// out.
+func statsOutput(out io.Writer, containersStats []podman.Stats) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "CONTAINER ID\tNAME\tCPU %%\tMEM USAGE / LIMIT\tMEM %%\tPIDS\n")
+
+	for _, containerStats := range containersStats {
+		memUsage := units.HumanSize(float64(containerStats.MemUsage))
+		memLimit := units.HumanSize(float64(containerStats.MemLimit))
+
+		fmt.Fprintf(writer, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%d\n",
+			utils.ShortID(containerStats.ID),
+			containerStats.Name,
+			containerStats.CPUPercent,
+			memUsage,
+			memLimit,
+			containerStats.MemPercent,
+			containerStats.PIDs)
+	}
+
+	writer.Flush()
+}
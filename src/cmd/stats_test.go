@@ -0,0 +1,66 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsOutput(t *testing.T) {
+	containersStats := []podman.Stats{
+		{
+			ID:         "4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01",
+			Name:       "fedora-toolbox-36",
+			CPUPercent: 0.15,
+			MemUsage:   12500000,
+			MemLimit:   16500000000,
+			MemPercent: 0.08,
+			PIDs:       3,
+		},
+		{
+			ID:         "d5e0b4e3c6b1",
+			Name:       "gegl",
+			CPUPercent: 101.5,
+			MemUsage:   2000000000,
+			MemLimit:   16500000000,
+			MemPercent: 12.12,
+			PIDs:       42,
+		},
+	}
+
+	var out strings.Builder
+	statsOutput(&out, containersStats)
+
+	expected := "" +
+		"CONTAINER ID  NAME               CPU %    MEM USAGE / LIMIT  MEM %   PIDS\n" +
+		"4c9f5ce5f4d4  fedora-toolbox-36  0.15%    12.5MB / 16.5GB    0.08%   3\n" +
+		"d5e0b4e3c6b1  gegl               101.50%  2GB / 16.5GB       12.12%  42\n"
+
+	assert.Equal(t, expected, out.String())
+}
+
+func TestStatsNonToolboxContainer(t *testing.T) {
+	shelltest.SetUp(t, podman.SetRunner, fakeInspectPodman)
+
+	err := stats(statsCmd, []string{"plain-container"})
+	assert.EqualError(t, err, "plain-container is not a toolbox container")
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stopFlags struct {
+		time uint
+	}
+)
+
+var stopCmd = &cobra.Command{
+	Use:               "stop",
+	Short:             "Stop one or more running toolbox containers",
+	RunE:              stop,
+	ValidArgsFunction: completionContainerNamesFiltered,
+}
+
+func init() {
+	flags := stopCmd.Flags()
+
+	flags.UintVarP(&stopFlags.time,
+		"time",
+		"t",
+		10,
+		"Seconds to wait for a container to stop before killing it")
+
+	stopCmd.SetHelpFunc(stopHelp)
+	rootCmd.AddCommand(stopCmd)
+}
+
+func stop(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if len(args) == 0 {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "missing argument for \"stop\"\n")
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	ctx, cancel := newInterruptibleContext()
+	defer cancel()
+
+	for _, container := range args {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if _, err := podman.IsToolboxContainer(container); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			continue
+		}
+
+		if err := podman.StopContext(ctx, container, stopFlags.time); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to stop container %s: %s\n", container, err)
+			continue
+		}
+	}
+
+	return nil
+}
+
+func stopHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-stop"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
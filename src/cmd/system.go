@@ -0,0 +1,304 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// diskUsage is the space used by toolbox images and containers. Layers that
+// an image shares with other images aren't counted, because removing the image
+// doesn't free them.
+type diskUsage struct {
+	images     []imageDiskUsage
+	containers []podman.Container
+
+	activeImages          int
+	imagesBytes           int64
+	imagesReclaimable     int64
+	activeContainers      int
+	containersBytes       int64
+	containersReclaimable int64
+}
+
+type imageDiskUsage struct {
+	name        string
+	containers  int
+	sizeBytes   int64
+	sharedBytes int64
+	uniqueBytes int64
+}
+
+var (
+	systemDfFlags struct {
+		verbose bool
+	}
+)
+
+var systemCmd = &cobra.Command{
+	Use:               "system",
+	Short:             "Manage toolbox containers and images as a whole",
+	RunE:              system,
+	ValidArgsFunction: completionEmpty,
+}
+
+var systemDfCmd = &cobra.Command{
+	Use:               "df",
+	Short:             "Show the disk space used by toolbox containers and images",
+	RunE:              systemDf,
+	ValidArgsFunction: completionEmpty,
+}
+
+func init() {
+	flags := systemDfCmd.Flags()
+
+	flags.BoolVarP(&systemDfFlags.verbose,
+		"verbose",
+		"v",
+		false,
+		"Show the space used by each container, image and shared layer")
+
+	systemCmd.SetHelpFunc(systemHelp)
+	systemDfCmd.SetHelpFunc(systemHelp)
+
+	systemCmd.AddCommand(systemDfCmd)
+	rootCmd.AddCommand(systemCmd)
+}
+
+func system(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "missing argument for \"system\"\n")
+	fmt.Fprintf(&builder, "Supported values are: df\n")
+	fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+	errMsg := builder.String()
+	return errors.New(errMsg)
+}
+
+func systemDf(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	images, err := podman.GetImages()
+	if err != nil {
+		logrus.Debugf("Fetching all images failed: %s", err)
+		return errors.New("failed to get images")
+	}
+
+	imagesDiskUsage, err := podman.GetImagesDiskUsage()
+	if err != nil {
+		logrus.Debugf("Fetching the disk usage of images failed: %s", err)
+		return errors.New("failed to get the disk usage of images")
+	}
+
+	containers, err := podman.GetContainers("--all", "--size")
+	if err != nil {
+		logrus.Debugf("Fetching all containers failed: %s", err)
+		return errors.New("failed to get containers")
+	}
+
+	containers = filterContainers(containers, false)
+
+	usage := getDiskUsage(images, imagesDiskUsage, containers)
+	systemDfOutput(os.Stdout, usage, systemDfFlags.verbose)
+	return nil
+}
+
+func systemHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := showManual("toolbox-system"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// getDiskUsage adds up the space used by the toolbox images among images, as
+// reported for each image in imagesDiskUsage, and by the writable layers of
+// the containers. An image is active if any container uses it, and a container
+// if it's running or paused. What is reclaimable is what 'toolbox prune' would
+// free. Images are named after their first name, or their short ID if they
+// have none. Unknown sizes count as 0.
+func getDiskUsage(images []podman.Image,
+	imagesDiskUsage []podman.ImageDiskUsage,
+	containers []podman.Container) diskUsage {
+	var usage diskUsage
+
+	seen := make(map[string]bool)
+
+	for _, image := range images {
+		if !image.IsToolbox() || seen[image.ID] {
+			continue
+		}
+
+		seen[image.ID] = true
+
+		name := utils.ShortID(image.ID)
+		if len(image.Names) != 0 {
+			name = image.Names[0]
+		}
+
+		imageUsage := imageDiskUsage{name: name}
+
+		for _, reported := range imagesDiskUsage {
+			if reported.ID != "" && strings.HasPrefix(image.ID, reported.ID) {
+				imageUsage.containers = reported.Containers
+				imageUsage.sizeBytes = reported.SizeBytes
+				imageUsage.sharedBytes = reported.SharedBytes
+				imageUsage.uniqueBytes = reported.UniqueBytes
+				break
+			}
+		}
+
+		usage.imagesBytes += imageUsage.uniqueBytes
+
+		if imageUsage.containers != 0 {
+			usage.activeImages++
+		} else {
+			usage.imagesReclaimable += imageUsage.uniqueBytes
+		}
+
+		usage.images = append(usage.images, imageUsage)
+	}
+
+	sort.Slice(usage.images, func(i, j int) bool {
+		return usage.images[i].name < usage.images[j].name
+	})
+
+	for _, container := range containers {
+		var size int64
+		if container.SizeBytes > 0 {
+			size = container.SizeBytes
+		}
+
+		usage.containersBytes += size
+
+		if container.Status == "running" || container.Status == "paused" {
+			usage.activeContainers++
+		} else {
+			usage.containersReclaimable += size
+		}
+
+		usage.containers = append(usage.containers, container)
+	}
+
+	return usage
+}
+
+// systemDfOutput writes a summary of the disk usage to out, followed by
+// tables with the images, containers and shared layers if verbose is true.
+func systemDfOutput(out io.Writer, usage diskUsage, verbose bool) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE\n")
+
+	fmt.Fprintf(writer, "Images\t%d\t%d\t%s\t%s\n",
+		len(usage.images),
+		usage.activeImages,
+		formatSize(usage.imagesBytes),
+		formatSize(usage.imagesReclaimable))
+
+	fmt.Fprintf(writer, "Containers\t%d\t%d\t%s\t%s\n",
+		len(usage.containers),
+		usage.activeContainers,
+		formatSize(usage.containersBytes),
+		formatSize(usage.containersReclaimable))
+
+	writer.Flush()
+
+	if !verbose {
+		return
+	}
+
+	if len(usage.images) != 0 {
+		fmt.Fprintf(out, "\n")
+
+		writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "IMAGE NAME\tCONTAINERS\tSIZE\tSHARED SIZE\tUNIQUE SIZE\n")
+
+		for _, image := range usage.images {
+			fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\n",
+				image.name,
+				image.containers,
+				formatSize(image.sizeBytes),
+				formatSize(image.sharedBytes),
+				formatSize(image.uniqueBytes))
+		}
+
+		writer.Flush()
+	}
+
+	if len(usage.containers) != 0 {
+		fmt.Fprintf(out, "\n")
+
+		writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "CONTAINER NAME\tSTATUS\tSIZE\n")
+
+		for _, container := range usage.containers {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n",
+				container.Names[0],
+				container.Status,
+				formatSize(container.SizeBytes))
+		}
+
+		writer.Flush()
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/stretchr/testify/assert"
+)
+
+var toolboxLabels = map[string]string{"com.github.containers.toolbox": "true"}
+
+func TestGetDiskUsage(t *testing.T) {
+	images := []podman.Image{
+		{ID: "8b9affd1dbc2e0f1", Names: []string{"fedora-toolbox:36"}, Labels: toolboxLabels},
+		{ID: "9c0b0ee2ecd3a4b5", Names: []string{"fedora-toolbox:37"}, Labels: toolboxLabels},
+		{ID: "9c0b0ee2ecd3a4b5", Names: []string{"fedora-toolbox:latest"}, Labels: toolboxLabels},
+		{ID: "e6f1c5f4d7c2b3a4", Labels: toolboxLabels},
+		{ID: "d5e0b4e3c6b1a2b3", Names: []string{"alpine:latest"}},
+	}
+
+	imagesDiskUsage := []podman.ImageDiskUsage{
+		{ID: "8b9affd1dbc2", SizeBytes: 1200, SharedBytes: 1000, UniqueBytes: 200, Containers: 2},
+		{ID: "9c0b0ee2ecd3", SizeBytes: 1300, SharedBytes: 1000, UniqueBytes: 300},
+		{ID: "e6f1c5f4d7c2", SizeBytes: 50, UniqueBytes: 50},
+		{ID: "d5e0b4e3c6b1", SizeBytes: 7000, UniqueBytes: 7000},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Status: "running", SizeBytes: 4096},
+		{ID: "d5e0b4e3c6b1", Names: []string{"old"}, Status: "exited", SizeBytes: 40960},
+		{ID: "e6f1c5f4d7c2", Names: []string{"new"}, Status: "created", SizeBytes: -1},
+	}
+
+	usage := getDiskUsage(images, imagesDiskUsage, containers)
+
+	expectedImages := []imageDiskUsage{
+		{name: "e6f1c5f4d7c2", sizeBytes: 50, uniqueBytes: 50},
+		{name: "fedora-toolbox:36", containers: 2, sizeBytes: 1200, sharedBytes: 1000, uniqueBytes: 200},
+		{name: "fedora-toolbox:37", sizeBytes: 1300, sharedBytes: 1000, uniqueBytes: 300},
+	}
+
+	assert.Equal(t, expectedImages, usage.images)
+	assert.Equal(t, 1, usage.activeImages)
+	assert.Equal(t, int64(550), usage.imagesBytes)
+	assert.Equal(t, int64(350), usage.imagesReclaimable)
+	assert.Equal(t, 1, usage.activeContainers)
+	assert.Equal(t, int64(45056), usage.containersBytes)
+	assert.Equal(t, int64(40960), usage.containersReclaimable)
+}
+
+func TestSystemDfOutput(t *testing.T) {
+	images := []podman.Image{
+		{ID: "8b9affd1dbc2e0f1", Names: []string{"fedora-toolbox:36"}, Labels: toolboxLabels},
+		{ID: "9c0b0ee2ecd3a4b5", Names: []string{"fedora-toolbox:37"}, Labels: toolboxLabels},
+	}
+
+	imagesDiskUsage := []podman.ImageDiskUsage{
+		{ID: "8b9affd1dbc2", SizeBytes: 1000000, SharedBytes: 1000000, Containers: 1},
+		{ID: "9c0b0ee2ecd3", SizeBytes: 3000000, SharedBytes: 1000000, UniqueBytes: 2000000},
+	}
+
+	containers := []podman.Container{
+		{ID: "4c9f5ce5f4d4", Names: []string{"fedora-toolbox-36"}, Status: "running", SizeBytes: 4000},
+	}
+
+	usage := getDiskUsage(images, imagesDiskUsage, containers)
+
+	var out strings.Builder
+	systemDfOutput(&out, usage, false)
+
+	expected := "" +
+		"TYPE        TOTAL  ACTIVE  SIZE  RECLAIMABLE\n" +
+		"Images      2      1       2MB   2MB\n" +
+		"Containers  1      1       4kB   0B\n"
+
+	assert.Equal(t, expected, out.String())
+
+	out.Reset()
+	systemDfOutput(&out, usage, true)
+
+	expectedVerbose := expected +
+		"\n" +
+		"IMAGE NAME         CONTAINERS  SIZE  SHARED SIZE  UNIQUE SIZE\n" +
+		"fedora-toolbox:36  1           1MB   1MB          0B\n" +
+		"fedora-toolbox:37  0           3MB   1MB          2MB\n" +
+		"\n" +
+		"CONTAINER NAME     STATUS   SIZE\n" +
+		"fedora-toolbox-36  running  4kB\n"
+
+	assert.Equal(t, expectedVerbose, out.String())
+}
+
+func TestSystemWithoutSubcommand(t *testing.T) {
+	err := system(systemCmd, nil)
+
+	expected := "missing argument for \"system\"\n" +
+		"Supported values are: df\n" +
+		"Run '" + executableBase + " --help' for usage."
+	assert.EqualError(t, err, expected)
+}
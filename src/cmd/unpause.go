@@ -0,0 +1,52 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unpauseFlags struct {
+		all bool
+	}
+)
+
+var unpauseCmd = &cobra.Command{
+	Use:               "unpause",
+	Short:             "Unpause one or more paused toolbox containers",
+	RunE:              unpause,
+	ValidArgsFunction: completionContainerNamesFiltered,
+}
+
+func init() {
+	flags := unpauseCmd.Flags()
+
+	flags.BoolVarP(&unpauseFlags.all, "all", "a", false, "Unpause all paused toolbox containers")
+
+	unpauseCmd.SetHelpFunc(unpauseHelp)
+	rootCmd.AddCommand(unpauseCmd)
+}
+
+func unpause(cmd *cobra.Command, args []string) error {
+	return pauseOrUnpause("unpause", unpauseFlags.all, args, podman.ResumeAll, podman.Resume)
+}
+
+func unpauseHelp(cmd *cobra.Command, args []string) {
+	pauseOrUnpauseHelp("unpause", cmd, args)
+}
@@ -18,14 +18,19 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
 	"syscall"
 
 	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
 )
 
 // askForConfirmation prints prompt to stdout and waits for response from the
@@ -37,15 +42,22 @@ import (
 //
 // The default answer is "no" ([y/N])
 func askForConfirmation(prompt string) bool {
+	return askForConfirmationFrom(os.Stdin, os.Stdout, prompt)
+}
+
+// askForConfirmationFrom is like askForConfirmation, but reads the response
+// from in and prints the prompt to out.
+func askForConfirmationFrom(in io.Reader, out io.Writer, prompt string) bool {
 	var retVal bool
 
+	scanner := bufio.NewScanner(in)
+	scanner.Split(bufio.ScanLines)
+
 	for {
-		fmt.Printf("%s ", prompt)
+		fmt.Fprintf(out, "%s ", prompt)
 
 		var response string
 
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Split(bufio.ScanLines)
 		if scanner.Scan() {
 			response = scanner.Text()
 		}
@@ -67,6 +79,54 @@ func askForConfirmation(prompt string) bool {
 	return retVal
 }
 
+// confirmRemoval asks the user whether count toolbox containers or images,
+// as described by noun, should be removed. There's nothing to ask if the
+// user already answered yes with '--assumeyes', or if stdin isn't a terminal,
+// so that scripts can remove things without being interrupted.
+func confirmRemoval(in io.Reader, out io.Writer, isTerminal bool, count int, noun string) bool {
+	if rootFlags.assumeYes || !isTerminal {
+		return true
+	}
+
+	prompt := fmt.Sprintf("Remove %d %s? [y/N]", count, noun)
+	return askForConfirmationFrom(in, out, prompt)
+}
+
+// isStdinTerminal checks if the standard input is a terminal, which means
+// that the user can answer questions.
+func isStdinTerminal() bool {
+	stdinFd := os.Stdin.Fd()
+	stdinFdInt := int(stdinFd)
+	return term.IsTerminal(stdinFdInt)
+}
+
+// newInterruptibleContext returns a context that is cancelled when toolbox
+// receives SIGINT or SIGTERM, for example because the user pressed Ctrl+C, so
+// that the Podman commands run with it are stopped. The returned function
+// must be called once the context isn't needed anymore.
+func newInterruptibleContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			logrus.Debugf("Received %s, cancelling", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	stop := func() {
+		signal.Stop(signals)
+		cancel()
+	}
+
+	return ctx, stop
+}
+
 func createErrorContainerNotFound(container string) error {
 	var builder strings.Builder
 	fmt.Fprintf(&builder, "container %s not found\n", container)
@@ -0,0 +1,118 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmRemoval(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		assumeYes  bool
+		isTerminal bool
+		expected   bool
+		prompts    int
+	}{
+		{
+			name:       "yes",
+			input:      "y\n",
+			isTerminal: true,
+			expected:   true,
+			prompts:    1,
+		},
+		{
+			name:       "no",
+			input:      "no\n",
+			isTerminal: true,
+			expected:   false,
+			prompts:    1,
+		},
+		{
+			name:       "default",
+			input:      "\n",
+			isTerminal: true,
+			expected:   false,
+			prompts:    1,
+		},
+		{
+			name:       "invalid answer, then yes",
+			input:      "maybe\nYES\n",
+			isTerminal: true,
+			expected:   true,
+			prompts:    2,
+		},
+		{
+			name:       "--assumeyes",
+			assumeYes:  true,
+			isTerminal: true,
+			expected:   true,
+		},
+		{
+			name:     "not a terminal",
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assumeYes := rootFlags.assumeYes
+			rootFlags.assumeYes = tc.assumeYes
+			t.Cleanup(func() {
+				rootFlags.assumeYes = assumeYes
+			})
+
+			var out strings.Builder
+			confirmed := confirmRemoval(strings.NewReader(tc.input), &out, tc.isTerminal, 3, "toolbox containers")
+
+			assert.Equal(t, tc.expected, confirmed)
+			assert.Equal(t, strings.Repeat("Remove 3 toolbox containers? [y/N] ", tc.prompts), out.String())
+		})
+	}
+}
+
+func TestNewInterruptibleContext(t *testing.T) {
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, stop := newInterruptibleContext()
+		defer stop()
+
+		err := syscall.Kill(os.Getpid(), syscall.SIGINT)
+		assert.NoError(t, err)
+
+		select {
+		case <-ctx.Done():
+			assert.ErrorIs(t, ctx.Err(), context.Canceled)
+		case <-time.After(5 * time.Second):
+			t.Fatal("context was not cancelled")
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		ctx, stop := newInterruptibleContext()
+		stop()
+
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
@@ -15,4 +15,5 @@ require (
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/sys v0.1.0
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,82 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package engine abstracts the container engine that toolbox containers and
+// images are managed with, so that commands don't need to know which one it
+// is. Podman is the only engine for now.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/containers/toolbox/pkg/podman"
+)
+
+// Engine is implemented by container engines. Containers and images are
+// returned as the types of package podman, because it is where toolbox's
+// notion of them lives.
+type Engine interface {
+	// Name returns the name of the engine, for example "podman".
+	Name() string
+
+	// Create creates a container. The arguments are those that follow
+	// 'create' on the engine's command line: the options, the image and
+	// the command of the container.
+	Create(args []string) error
+
+	// Exec runs a command in a running container and returns its exit
+	// code. The arguments are those that follow 'exec' on the engine's
+	// command line: the options, the container and the command. An error
+	// is only returned if the engine couldn't be run.
+	Exec(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+
+	// Inspect returns the inspect document of a container or an image,
+	// depending on typeArg.
+	Inspect(typeArg, target string) (map[string]interface{}, error)
+
+	// ListContainers returns all containers, including stopped ones,
+	// sorted by name. The engine is stopped if ctx is done before it
+	// finishes.
+	ListContainers(ctx context.Context) ([]podman.Container, error)
+
+	// ListImages returns all images. The engine is stopped if ctx is done
+	// before it finishes.
+	ListImages(ctx context.Context) ([]podman.Image, error)
+
+	// Pull pulls an image. The engine is stopped if ctx is done before
+	// it finishes.
+	Pull(ctx context.Context, image string, options podman.PullOptions) error
+
+	// RemoveContainer removes a container. Running containers are only
+	// removed if forceDelete is true.
+	RemoveContainer(container string, forceDelete bool) error
+
+	// RemoveImage removes an image. Images used by containers are only
+	// removed, together with the containers, if forceDelete is true.
+	RemoveImage(image string, forceDelete bool) error
+}
+
+// New returns the Engine called name, as returned by its Name method.
+func New(name string) (Engine, error) {
+	switch name {
+	case "podman":
+		return NewPodman(), nil
+	default:
+		return nil, fmt.Errorf("container engine %s is not supported", name)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package engine
+
+import (
+	"context"
+	"io"
+
+	"github.com/containers/toolbox/pkg/podman"
+)
+
+type podmanEngine struct{}
+
+var _ Engine = podmanEngine{}
+
+// NewPodman returns the Engine that runs Podman.
+func NewPodman() Engine {
+	return podmanEngine{}
+}
+
+func (podmanEngine) Name() string {
+	return "podman"
+}
+
+func (podmanEngine) Create(args []string) error {
+	return podman.CreateContainer(args)
+}
+
+func (podmanEngine) Exec(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	return podman.Exec(args, stdin, stdout, stderr)
+}
+
+func (podmanEngine) Inspect(typeArg, target string) (map[string]interface{}, error) {
+	return podman.Inspect(typeArg, target)
+}
+
+func (podmanEngine) ListContainers(ctx context.Context) ([]podman.Container, error) {
+	return podman.GetContainersContext(ctx, "--all", "--sort", "names")
+}
+
+func (podmanEngine) ListImages(ctx context.Context) ([]podman.Image, error) {
+	return podman.GetImagesContext(ctx)
+}
+
+func (podmanEngine) Pull(ctx context.Context, image string, options podman.PullOptions) error {
+	return podman.PullContext(ctx, image, options)
+}
+
+func (podmanEngine) RemoveContainer(container string, forceDelete bool) error {
+	return podman.RemoveContainer(container, forceDelete)
+}
+
+func (podmanEngine) RemoveImage(image string, forceDelete bool) error {
+	return podman.RemoveImage(image, forceDelete)
+}
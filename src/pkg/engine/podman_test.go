@@ -0,0 +1,112 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	containerEngine, err := New("podman")
+	require.NoError(t, err)
+	assert.Equal(t, "podman", containerEngine.Name())
+
+	_, err = New("docker")
+	assert.EqualError(t, err, "container engine docker is not supported")
+}
+
+func TestPodmanName(t *testing.T) {
+	assert.Equal(t, "podman", NewPodman().Name())
+}
+
+func TestPodmanCreate(t *testing.T) {
+	r := shelltest.SetUp(t, podman.SetRunner, shelltest.Exit(0))
+
+	err := NewPodman().Create([]string{"--name", "fedora-toolbox-36", "fedora-toolbox:36", "toolbox", "init-container"})
+	require.NoError(t, err)
+
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "--log-level error create --name fedora-toolbox-36 fedora-toolbox:36 toolbox init-container", calls[0])
+}
+
+func TestPodmanExec(t *testing.T) {
+	r := shelltest.SetUp(t, podman.SetRunner, shelltest.Output("/usr/bin/gegl\n"))
+
+	var stdout strings.Builder
+	exitCode, err := NewPodman().Exec([]string{"fedora-toolbox-36", "command", "-v", "gegl"}, nil, &stdout, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "/usr/bin/gegl\n", stdout.String())
+
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "--log-level error exec fedora-toolbox-36 command -v gegl", calls[0])
+}
+
+func TestPodmanRemoveContainer(t *testing.T) {
+	r := shelltest.SetUp(t, podman.SetRunner, shelltest.Exit(0))
+
+	err := NewPodman().RemoveContainer("fedora-toolbox-36", true)
+	require.NoError(t, err)
+
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "--log-level error rm --force fedora-toolbox-36", calls[0])
+}
+
+func TestPodmanListContainers(t *testing.T) {
+	r := shelltest.SetUp(t, podman.SetRunner, shelltest.Output(`[
+		{"Id": "4c9f5ce5f4d4", "Names": ["fedora-toolbox-36"], "Labels": {"com.github.containers.toolbox": "true"}},
+		{"Id": "e6f1c5f4d7c2", "Names": ["web-server"]}
+	]`))
+
+	containers, err := NewPodman().ListContainers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+	assert.Equal(t, "fedora-toolbox-36", containers[0].Names[0])
+	assert.True(t, containers[0].IsToolbox())
+	assert.Equal(t, "web-server", containers[1].Names[0])
+	assert.False(t, containers[1].IsToolbox())
+
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "--log-level error ps --format json --all --sort names", calls[0])
+}
+
+func TestPodmanListImages(t *testing.T) {
+	r := shelltest.SetUp(t, podman.SetRunner, shelltest.Output(`[
+		{"Id": "8b9affd1dbc2", "Names": ["fedora-toolbox:36"], "Labels": {"com.github.containers.toolbox": "true"}}
+	]`))
+
+	images, err := NewPodman().ListImages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "8b9affd1dbc2", images[0].ID)
+	assert.Equal(t, []string{"fedora-toolbox:36"}, images[0].Names)
+
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "--log-level error images --format json", calls[0])
+}
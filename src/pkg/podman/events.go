@@ -0,0 +1,107 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event is a change to a container or an image reported by Podman.
+type Event struct {
+	// Type is either "container" or "image".
+	Type string
+
+	// Status is what happened, like "create", "start", "died" or
+	// "remove" for containers, or "pull" and "remove" for images.
+	Status string
+
+	ID   string
+	Name string
+}
+
+// eventWriter calls handler for each line of JSON that 'podman events'
+// prints on its standard output.
+type eventWriter struct {
+	buffer  []byte
+	handler func(Event)
+}
+
+// WatchEvents calls handler for every change to a container or an image from
+// the time it's called until ctx is done, when it returns nil. The handler is
+// called from another goroutine. It returns an error if Podman can't report
+// the changes, or stops doing so by itself.
+func WatchEvents(ctx context.Context, handler func(Event)) error {
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{
+		"--log-level", logLevelString,
+		"events",
+		"--filter", "type=container",
+		"--filter", "type=image",
+		"--format", "json",
+	}
+
+	stdout := &eventWriter{handler: handler}
+
+	if err := run(ctx, nil, nil, stdout, getStderr(&stderr), args...); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return errorWithStderr(err, &stderr)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return errors.New("podman(1) stopped reporting events")
+}
+
+func (w *eventWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+
+	for {
+		i := bytes.IndexByte(w.buffer, '\n')
+		if i == -1 {
+			break
+		}
+
+		line := bytes.TrimSpace(w.buffer[:i])
+		w.buffer = w.buffer[i+1:]
+
+		if len(line) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			logrus.Debugf("Parsing event %s failed: %s", line, err)
+			continue
+		}
+
+		w.handler(event)
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchEvents(t *testing.T) {
+	t.Run("cancelled", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			fmt.Fprintln(call.Stdout, `{"ID":"4c9f5ce5f4d4","Name":"fedora-toolbox-36","Status":"start","Type":"container"}`)
+			fmt.Fprintln(call.Stdout, "not JSON")
+			fmt.Fprintln(call.Stdout, `{"ID":"8b9affd1dbc2","Name":"fedora-toolbox:36","Status":"pull","Type":"image"}`)
+			return shelltest.Hang(call)
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var events []Event
+		err := WatchEvents(ctx, func(event Event) {
+			events = append(events, event)
+			if len(events) == 2 {
+				cancel()
+			}
+		})
+
+		assert.NoError(t, err)
+
+		expected := []Event{
+			{Type: "container", Status: "start", ID: "4c9f5ce5f4d4", Name: "fedora-toolbox-36"},
+			{Type: "image", Status: "pull", ID: "8b9affd1dbc2", Name: "fedora-toolbox:36"},
+		}
+		assert.Equal(t, expected, events)
+
+		calls := r.Calls()
+		assert.Equal(t,
+			[]string{"--log-level error events --filter type=container --filter type=image --format json"},
+			calls)
+	})
+
+	t.Run("Podman exits", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Exit(0))
+
+		err := WatchEvents(context.Background(), func(Event) {})
+		assert.EqualError(t, err, "podman(1) stopped reporting events")
+	})
+
+	t.Run("Podman fails", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner,
+			shelltest.Fail("Error: failed to read events: journal not available\n", 125))
+
+		err := WatchEvents(context.Background(), func(Event) {})
+		assert.EqualError(t, err, "failed to invoke podman(1): failed to read events: journal not available")
+	})
+}
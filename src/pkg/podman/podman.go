@@ -18,42 +18,390 @@ package podman
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/HarryMichal/go-version"
 	"github.com/containers/toolbox/pkg/shell"
 	"github.com/containers/toolbox/pkg/utils"
+	"github.com/docker/go-units"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
 )
 
+type Container struct {
+	ID        string
+	Names     []string
+	Status    string
+	Created   string
+	CreatedAt time.Time
+	Image     string
+	Command   []string
+	Labels    map[string]string
+	Pid       int
+
+	// ImageID is the ID of the image that the container was created from.
+	// It's empty with Podman V1.
+	ImageID string
+
+	// ExitCode is the exit code of the container's process the last time
+	// that it exited. It's 0 if the container never ran or is running.
+	ExitCode int
+
+	// ImageMissing is set by FindMissingImages if the image that the
+	// container was created from doesn't exist anymore.
+	ImageMissing bool
+
+	// StartedAt is when the container's task was last started, or the zero
+	// time if it never was or Podman didn't report it.
+	StartedAt time.Time
+
+	// ExitedAt is when the container's task last exited, or the zero time
+	// if it never did or Podman didn't report it.
+	ExitedAt time.Time
+
+	// SizeBytes is the size of the container's writable layer in bytes,
+	// or -1 if unknown. Podman only reports it with 'podman ps --size'.
+	SizeBytes int64
+}
+
+// DiskUsage is the space used by Podman's local storage, in bytes. Podman has
+// no namespaces, so it covers everything, not only toolbox containers and
+// images.
+type DiskUsage struct {
+	Images     int64
+	Containers int64
+	Volumes    int64
+}
+
 type Image struct {
-	ID      string
-	Names   []string
-	Created string
-	Labels  map[string]string
+	ID        string
+	Names     []string
+	Created   string
+	CreatedAt time.Time
+	Labels    map[string]string
+
+	// SizeBytes is the size of the image in bytes, or -1 if unknown.
+	SizeBytes int64
+
+	// Platform is the image's OS and architecture, like "linux/arm64/v8",
+	// as filled in by GetImagePlatforms. It is empty if unknown, or if the
+	// image is a manifest list that covers several platforms.
+	Platform string
+}
+
+// ImageDiskUsage is the space used by an image in local storage, as reported
+// by 'podman system df --verbose'.
+type ImageDiskUsage struct {
+	// ID is the short ID of the image.
+	ID string
+
+	SizeBytes int64
+
+	// SharedBytes is the size of the layers that other images share,
+	// including ones that aren't toolbox images.
+	SharedBytes int64
+
+	// UniqueBytes is the size of the layers that only this image uses.
+	UniqueBytes int64
+
+	// Containers is the number of containers that use the image.
+	Containers int
 }
 
 type ImageSlice []Image
 
+// Layer is a layer of an image in local storage.
+type Layer struct {
+	Digest string
+
+	// SizeBytes is the space used by the uncompressed layer on disk, or -1
+	// if unknown.
+	SizeBytes int64
+}
+
+// LogsOptions holds the optional parameters of Logs.
+type LogsOptions struct {
+	// Follow keeps streaming new output until the container stops or the
+	// context is done.
+	Follow bool
+
+	// Tail limits the output to the last Tail lines that were logged
+	// before Logs was called. All lines are shown if it's 0.
+	Tail int
+}
+
+// PullOptions holds the optional parameters of Pull.
+type PullOptions struct {
+	// AuthFile is a path to a JSON authentication file.
+	AuthFile string
+
+	// Username and Password are used to log in to the registry of the
+	// image instead of an authentication file. Podman gets them through a
+	// temporary file that only the user can read, not its command line.
+	Username string
+	Password string
+
+	// RemoveOnDigestMismatch removes the pulled image if it was requested
+	// by digest and the digest of the stored image doesn't match.
+	RemoveOnDigestMismatch bool
+
+	// RegistriesConf is a path to a registries.conf(5) file used instead
+	// of the system-wide configuration, for example to redirect docker.io
+	// to a mirror. Podman also honours the CONTAINERS_REGISTRIES_CONF
+	// environment variable.
+	RegistriesConf string
+
+	// Platform, if not empty, selects the image for another platform, like
+	// linux/arm64, from a manifest list instead of the one for the host.
+	Platform string
+
+	// Progress, if not nil, is called once for each layer of the image
+	// when Podman starts copying it or skips it. Podman doesn't report how
+	// many bytes of a layer were copied when its standard error isn't a
+	// terminal, so there are no finer grained updates.
+	Progress func(PullProgress)
+}
+
+// PullProgress describes a layer of an image being pulled.
+type PullProgress struct {
+	// Digest is the digest of the layer as printed by Podman.
+	Digest string
+
+	// Skipped is true if the layer was already in local storage.
+	Skipped bool
+}
+
+// pullProgressWriter calls progress for each 'Copying blob' line that Podman
+// prints on its standard error while pulling. Each layer is reported once.
+type pullProgressWriter struct {
+	buffer   []byte
+	progress func(PullProgress)
+	seen     map[string]bool
+}
+
+// wrappedError has a message of its own, but errors.Is and errors.As look at
+// the error that it wraps.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+const (
+	// podmanVersionMinimum is the oldest Podman version whose output can
+	// be parsed.
+	podmanVersionMinimum = "1.4.0"
+)
+
 var (
 	podmanVersion string
+
+	// listRetries is how many more times a listing is attempted after it
+	// failed because of a concurrent operation.
+	listRetries = 2
+
+	listRetryDelay = 100 * time.Millisecond
+
+	// transientErrors are messages from Podman caused by a container or
+	// image being removed, or the storage being locked, while it was
+	// being listed.
+	transientErrors = []string{
+		"database is locked",
+		"no such container",
+		"no such image",
+		"no such object",
+		"resource temporarily unavailable",
+	}
+)
+
+var (
+	// ErrPermissionDenied is wrapped by errors caused by Podman not being
+	// allowed to access its storage or runtime files, for example because
+	// they were created by running Podman with sudo.
+	ErrPermissionDenied = errors.New("insufficient permissions to use Podman")
+
+	// ErrContainerNotFound, ErrContainerRunning, ErrImageNotFound,
+	// ErrImageHasChildren and ErrImageInUse are wrapped by the errors about
+	// a given container or image, so that they can be told apart with
+	// errors.Is. The messages of the wrapping errors name the container or
+	// image.
+	ErrContainerNotFound = errors.New("container does not exist")
+	ErrContainerRunning  = errors.New("container is running")
+	ErrImageNotFound     = errors.New("image does not exist")
+	ErrImageHasChildren  = errors.New("image has dependent children")
+	ErrImageInUse        = errors.New("image is used by containers")
 )
 
 var (
 	LogLevel = logrus.ErrorLevel
+
+	runner shell.Runner = shell.ExecRunner{}
 )
 
-func (image *Image) FlattenNames(fillNameWithID bool) []Image {
+// IsToolbox checks if the container has one of the labels that mark it as a
+// toolbox container, without inspecting it again.
+func (container *Container) IsToolbox() bool {
+	return hasToolboxLabel(container.Labels)
+}
+
+func (container *Container) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID        string
+		Names     interface{}
+		Status    string
+		State     interface{}
+		Created   interface{}
+		Image     string
+		ImageID   string
+		Command   interface{}
+		Labels    map[string]string
+		Pid       int
+		ExitCode  int
+		StartedAt interface{}
+		ExitedAt  interface{}
+		Size      *struct {
+			RwSize int64
+		}
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	container.ID = raw.ID
+
+	// In Podman V1 the field 'Names' held a single string but since Podman V2 the
+	// field holds an array of strings
+	container.Names = nil
+
+	switch value := raw.Names.(type) {
+	case string:
+		container.Names = append(container.Names, value)
+	case []interface{}:
+		for _, v := range value {
+			if name, ok := v.(string); ok {
+				container.Names = append(container.Names, name)
+			}
+		}
+	}
+
+	// In Podman V1 the field holding a string about the container's state was
+	// called 'Status' and field 'State' held a number representing the state. In
+	// Podman V2 the string was moved to 'State' and field 'Status' was dropped.
+	switch value := raw.State.(type) {
+	case string:
+		container.Status = NormalizeTaskState(value)
+	case float64:
+		container.Status = NormalizeTaskState(raw.Status)
+	default:
+		container.Status = NormalizeTaskState("")
+	}
+
+	// Since Podman V2 the exit code is in field 'ExitCode', while Podman V1
+	// only had it in the 'Status' string, in format "Exited (1) 5 minutes
+	// ago".
+	container.ExitCode = raw.ExitCode
+
+	if _, ok := raw.State.(float64); ok {
+		var exitCode int
+		if _, err := fmt.Sscanf(raw.Status, "Exited (%d)", &exitCode); err == nil {
+			container.ExitCode = exitCode
+		}
+	}
+
+	// In Podman V1 the field 'Created' held a human-readable string in format
+	// "5 minutes ago". Since Podman V2 the field holds an integer with Unix time.
+	// After a discussion in https://github.com/containers/podman/issues/6594 the
+	// previous value was moved to field 'CreatedAt'. Since we're already using
+	// the 'github.com/docker/go-units' library, we'll stop using the provided
+	// human-readable string and assemble it ourselves. Go interprets numbers in
+	// JSON as float64.
+	switch value := raw.Created.(type) {
+	case string:
+		container.Created = value
+	case float64:
+		container.Created = utils.HumanDuration(int64(value))
+		container.CreatedAt = time.Unix(int64(value), 0)
+	}
+
+	container.Image = raw.Image
+	container.ImageID = raw.ImageID
+
+	// In Podman V1 the field 'Command' held the command as a single string,
+	// but since Podman V2 it holds an array with the command and its
+	// arguments.
+	container.Command = nil
+
+	switch value := raw.Command.(type) {
+	case string:
+		container.Command = strings.Fields(value)
+	case []interface{}:
+		for _, v := range value {
+			if arg, ok := v.(string); ok {
+				container.Command = append(container.Command, arg)
+			}
+		}
+	}
+
+	container.Labels = raw.Labels
+	container.Pid = raw.Pid
+
+	// Since Podman V2 the field 'StartedAt' holds an integer with Unix time,
+	// which is 0 or negative if the container was never started.
+	container.StartedAt = time.Time{}
+	if value, ok := raw.StartedAt.(float64); ok && value > 0 {
+		container.StartedAt = time.Unix(int64(value), 0)
+	}
+
+	// The same goes for the field 'ExitedAt'.
+	container.ExitedAt = time.Time{}
+	if value, ok := raw.ExitedAt.(float64); ok && value > 0 {
+		container.ExitedAt = time.Unix(int64(value), 0)
+	}
+
+	container.SizeBytes = -1
+	if raw.Size != nil {
+		container.SizeBytes = raw.Size.RwSize
+	}
+
+	return nil
+}
+
+// FlattenNames returns one copy of the image for each of its names.
+//
+// An image without any names is returned as a single copy named "<none>", or,
+// if fillNameWithID is true, named after its ID. The ID is shortened with
+// utils.ShortID unless fullID is true. The ID field itself is never
+// shortened.
+//
+// Toolbox's getImages uses short IDs, because the names end up in the output
+// of 'toolbox list' and in shell completion, where they match the IDs shown
+// by listOutput.
+func (image *Image) FlattenNames(fillNameWithID, fullID bool) []Image {
 	var ret []Image
 
 	if len(image.Names) == 0 {
 		flattenedImage := *image
 
 		if fillNameWithID {
-			shortID := utils.ShortID(image.ID)
-			flattenedImage.Names = []string{shortID}
+			id := image.ID
+			if !fullID {
+				id = utils.ShortID(id)
+			}
+
+			flattenedImage.Names = []string{id}
 		} else {
 			flattenedImage.Names = []string{"<none>"}
 		}
@@ -73,12 +421,19 @@ func (image *Image) FlattenNames(fillNameWithID bool) []Image {
 	return ret
 }
 
+// IsToolbox checks if the image has one of the labels that mark it as
+// compatible with Toolbox, without inspecting it again.
+func (image *Image) IsToolbox() bool {
+	return hasToolboxLabel(image.Labels)
+}
+
 func (image *Image) UnmarshalJSON(data []byte) error {
 	var raw struct {
 		ID      string
-		Names   []string
+		Names   interface{}
 		Created interface{}
 		Labels  map[string]string
+		Size    interface{}
 	}
 
 	if err := json.Unmarshal(data, &raw); err != nil {
@@ -86,7 +441,21 @@ func (image *Image) UnmarshalJSON(data []byte) error {
 	}
 
 	image.ID = raw.ID
-	image.Names = raw.Names
+
+	// Like for containers, some Podman versions give the names of an image
+	// as a single string instead of an array of strings.
+	image.Names = nil
+
+	switch value := raw.Names.(type) {
+	case string:
+		image.Names = append(image.Names, value)
+	case []interface{}:
+		for _, v := range value {
+			if name, ok := v.(string); ok {
+				image.Names = append(image.Names, name)
+			}
+		}
+	}
 
 	// Until Podman 2.0.x the field 'Created' held a human-readable string in
 	// format "5 minutes ago". Since Podman 2.1 the field holds an integer with
@@ -96,9 +465,24 @@ func (image *Image) UnmarshalJSON(data []byte) error {
 		image.Created = value
 	case float64:
 		image.Created = utils.HumanDuration(int64(value))
+		image.CreatedAt = time.Unix(int64(value), 0)
 	}
 
 	image.Labels = raw.Labels
+
+	// Podman V1 reported the size as a human-readable string in some
+	// versions. Go interprets numbers in JSON as float64.
+	image.SizeBytes = -1
+
+	switch value := raw.Size.(type) {
+	case string:
+		if size, err := units.FromHumanSize(value); err == nil {
+			image.SizeBytes = size
+		}
+	case float64:
+		image.SizeBytes = int64(value)
+	}
+
 	return nil
 }
 
@@ -106,16 +490,23 @@ func (images ImageSlice) Len() int {
 	return len(images)
 }
 
+// Less sorts images by name. It's meant to be used on an ImageSlice where
+// every image was flattened with FlattenNames, and so has exactly one name.
+// Unflattened images don't cause a panic, because they come from parsing
+// the output of podman(1), but are sorted last by ID.
 func (images ImageSlice) Less(i, j int) bool {
-	if len(images[i].Names) != 1 {
-		panic("cannot sort unflattened ImageSlice")
+	iFlattened := len(images[i].Names) == 1
+	jFlattened := len(images[j].Names) == 1
+
+	if iFlattened && jFlattened {
+		return images[i].Names[0] < images[j].Names[0]
 	}
 
-	if len(images[j].Names) != 1 {
-		panic("cannot sort unflattened ImageSlice")
+	if iFlattened != jFlattened {
+		return iFlattened
 	}
 
-	return images[i].Names[0] < images[j].Names[0]
+	return images[i].ID < images[j].ID
 }
 
 func (images ImageSlice) Swap(i, j int) {
@@ -136,239 +527,1558 @@ func CheckVersion(requiredVersion string) bool {
 	return version.CompareSimple(currentVersion, requiredVersion) >= 0
 }
 
+// CheckVersionFresh is like CheckVersion, but asks Podman for its version again
+// instead of using the one found earlier, in case it was upgraded meanwhile.
+func CheckVersionFresh(requiredVersion string) bool {
+	ResetVersionCache()
+	return CheckVersion(requiredVersion)
+}
+
 // ContainerExists checks using Podman if a container with given ID/name exists.
 //
 // Parameter container is a name or an id of a container.
 func ContainerExists(container string) (bool, error) {
+	var stderr bytes.Buffer
+
 	logLevelString := LogLevel.String()
 	args := []string{"--log-level", logLevelString, "container", "exists", container}
 
-	exitCode, err := shell.RunWithExitCode("podman", nil, nil, nil, args...)
+	exitCode, err := runWithExitCode(context.Background(), nil, nil, nil, getStderr(&stderr), args...)
 	if exitCode != 0 && err == nil {
 		err = fmt.Errorf("failed to find container %s", container)
 	}
 
 	if err != nil {
-		return false, err
+		return false, errorWithStderr(err, &stderr)
 	}
 
 	return true, nil
 }
 
-// GetContainers is a wrapper function around `podman ps --format json` command.
-//
-// Parameter args accepts an array of strings to be passed to the wrapped command (eg. ["-a", "--filter", "123"]).
-//
-// Returned value is a slice of dynamically unmarshalled json, so it needs to be treated properly.
-//
-// If a problem happens during execution, first argument is nil and second argument holds the error message.
-func GetContainers(args ...string) ([]map[string]interface{}, error) {
-	var stdout bytes.Buffer
+// CopyToContainer copies a file or a directory from the host into a running
+// container.
+func CopyToContainer(container, srcPath, destPath string) error {
+	if _, err := os.Stat(srcPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("source %s does not exist", srcPath)
+		}
 
-	logLevelString := LogLevel.String()
-	args = append([]string{"--log-level", logLevelString, "ps", "--format", "json"}, args...)
+		return fmt.Errorf("failed to access source %s", srcPath)
+	}
 
-	if err := shell.Run("podman", nil, &stdout, nil, args...); err != nil {
-		return nil, err
+	if err := ensureContainerRunning(container); err != nil {
+		return err
 	}
 
-	output := stdout.Bytes()
-	var containers []map[string]interface{}
+	var stderr bytes.Buffer
 
-	if err := json.Unmarshal(output, &containers); err != nil {
-		return nil, err
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "cp", srcPath, container + ":" + destPath}
+
+	if err := run(context.Background(), nil, nil, nil, getStderr(&stderr), args...); err != nil {
+		err = errorWithStderr(err, &stderr)
+		return fmt.Errorf("failed to copy %s to container %s: %w", srcPath, container, err)
 	}
 
-	return containers, nil
+	return nil
 }
 
-// GetImages is a wrapper function around `podman images --format json` command.
-//
-// Parameter args accepts an array of strings to be passed to the wrapped command (eg. ["-a", "--filter", "123"]).
-//
-// Returned value is a slice of Images.
-//
-// If a problem happens during execution, first argument is nil and second argument holds the error message.
-func GetImages(args ...string) ([]Image, error) {
-	var stdout bytes.Buffer
+// CreateContainer creates a container with 'podman create'. The arguments
+// are those that follow 'create', that is the options, the image and the
+// command of the container.
+func CreateContainer(args []string) error {
+	var stderr bytes.Buffer
 
 	logLevelString := LogLevel.String()
-	args = append([]string{"--log-level", logLevelString, "images", "--format", "json"}, args...)
-	if err := shell.Run("podman", nil, &stdout, nil, args...); err != nil {
-		return nil, err
-	}
+	args = append([]string{"--log-level", logLevelString, "create"}, args...)
 
-	data := stdout.Bytes()
-	var images []Image
-	if err := json.Unmarshal(data, &images); err != nil {
-		return nil, err
+	if err := run(context.Background(), nil, nil, nil, getStderr(&stderr), args...); err != nil {
+		return errorWithStderr(err, &stderr)
 	}
 
-	return images, nil
+	return nil
 }
 
-// GetVersion returns version of Podman in a string
-func GetVersion() (string, error) {
-	if podmanVersion != "" {
-		return podmanVersion, nil
+// EnsureSupportedBackend checks that the version of Podman is new enough for
+// its output to be understood.
+func EnsureSupportedBackend() error {
+	currentVersion, err := GetVersion()
+	if err != nil {
+		logrus.Debugf("Getting the Podman version failed: %s", err)
+		return errors.New("failed to get the Podman version")
 	}
 
-	var stdout bytes.Buffer
-
-	logLevelString := LogLevel.String()
-	args := []string{"--log-level", logLevelString, "version", "--format", "json"}
+	if !CheckVersion(podmanVersionMinimum) {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "Podman %s is not supported\n", currentVersion)
+		fmt.Fprintf(&builder, "Upgrade to Podman %s or newer.", podmanVersionMinimum)
 
-	if err := shell.Run("podman", nil, &stdout, nil, args...); err != nil {
-		return "", err
+		errMsg := builder.String()
+		return errors.New(errMsg)
 	}
 
-	output := stdout.Bytes()
-	var jsonoutput map[string]interface{}
-	if err := json.Unmarshal(output, &jsonoutput); err != nil {
-		return "", err
-	}
+	return nil
+}
 
-	podmanClientInfoInterface := jsonoutput["Client"]
-	switch podmanClientInfo := podmanClientInfoInterface.(type) {
-	case nil:
-		podmanVersion = jsonoutput["Version"].(string)
-	case map[string]interface{}:
-		podmanVersion = podmanClientInfo["Version"].(string)
+// Enter runs the user's login shell inside a container, starting the
+// container first if it isn't running. A pseudo-terminal is allocated if
+// stdout is a terminal.
+func Enter(container string, stdin io.Reader, stdout, stderr io.Writer) error {
+	running, err := TaskRunning(container)
+	if err != nil {
+		return err
 	}
-	return podmanVersion, nil
-}
 
-// ImageExists checks using Podman if an image with given ID/name exists.
-//
-// Parameter image is a name or an id of an image.
-func ImageExists(image string) (bool, error) {
-	logLevelString := LogLevel.String()
-	args := []string{"--log-level", logLevelString, "image", "exists", image}
+	if !running {
+		logrus.Debugf("Starting container %s", container)
 
-	exitCode, err := shell.RunWithExitCode("podman", nil, nil, nil, args...)
-	if exitCode != 0 && err == nil {
-		err = fmt.Errorf("failed to find image %s", image)
+		if err := Start(container, stderr); err != nil {
+			return fmt.Errorf("failed to start container %s", container)
+		}
 	}
 
+	currentUser, err := user.Current()
 	if err != nil {
-		return false, err
+		return errors.New("failed to get the current user")
 	}
 
-	return true, nil
+	userShell := os.Getenv("SHELL")
+	if userShell == "" {
+		return errors.New("failed to get the current user's default shell")
+	}
+
+	args := getEnterArgs(container, currentUser.Username, userShell, isTerminal(stdout))
+
+	logrus.Debugf("Entering container %s with %s", container, userShell)
+
+	if err := run(context.Background(), nil, stdin, stdout, stderr, args...); err != nil {
+		return fmt.Errorf("failed to enter container %s", container)
+	}
+
+	return nil
 }
 
-// Inspect is a wrapper around 'podman inspect' command
+// Exec runs a command in a running container with 'podman exec', and
+// returns its exit code. The arguments are those that follow 'exec', that is
+// the options, the container and the command. Like shell.RunWithExitCode, an
+// error is only returned if podman(1) couldn't be run.
+func Exec(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	logLevelString := LogLevel.String()
+	args = append([]string{"--log-level", logLevelString, "exec"}, args...)
+
+	return runWithExitCode(context.Background(), nil, stdin, stdout, stderr, args...)
+}
+
+// GetContainers is a wrapper function around `podman ps --format json` command.
 //
-// Parameter 'typearg' takes in values 'container' or 'image' that is passed to the --type flag
-func Inspect(typearg string, target string) (map[string]interface{}, error) {
-	var stdout bytes.Buffer
+// Parameter args accepts an array of strings to be passed to the wrapped command (eg. ["-a", "--filter", "123"]).
+//
+// Returned value is a slice of Containers.
+//
+// If a problem happens during execution, first argument is nil and second argument holds the error message.
+func GetContainers(args ...string) ([]Container, error) {
+	return GetContainersContext(context.Background(), args...)
+}
 
+// GetContainersContext is like GetContainers, but 'podman ps' is killed and
+// no more attempts are made if ctx is done before it finishes. The returned
+// error then wraps ctx.Err().
+func GetContainersContext(ctx context.Context, args ...string) ([]Container, error) {
 	logLevelString := LogLevel.String()
-	args := []string{"--log-level", logLevelString, "inspect", "--format", "json", "--type", typearg, target}
+	args = append([]string{"--log-level", logLevelString, "ps", "--format", "json"}, args...)
 
-	if err := shell.Run("podman", nil, &stdout, nil, args...); err != nil {
+	output, err := runListCommand(ctx, args)
+	if err != nil {
 		return nil, err
 	}
 
-	output := stdout.Bytes()
-	var info []map[string]interface{}
+	var containers []Container
 
-	if err := json.Unmarshal(output, &info); err != nil {
+	if err := json.Unmarshal(output, &containers); err != nil {
 		return nil, err
 	}
 
-	return info[0], nil
+	return containers, nil
 }
 
-func IsToolboxContainer(container string) (bool, error) {
-	info, err := Inspect("container", container)
+// GetContainersUsingImage returns the names of all containers, running or not,
+// that were created from the given image. Removing the image with
+// forceDelete removes them too.
+func GetContainersUsingImage(image string) ([]string, error) {
+	args := []string{"--all", "--filter", "ancestor=" + image}
+	containers, err := GetContainers(args...)
 	if err != nil {
-		return false, fmt.Errorf("failed to inspect container %s", container)
+		return nil, fmt.Errorf("failed to get containers using image %s", image)
 	}
 
-	labels, _ := info["Config"].(map[string]interface{})["Labels"].(map[string]interface{})
-	if labels["com.github.containers.toolbox"] != "true" && labels["com.github.debarshiray.toolbox"] != "true" {
-		return false, fmt.Errorf("%s is not a toolbox container", container)
+	var names []string
+
+	for _, container := range containers {
+		if len(container.Names) != 0 {
+			names = append(names, container.Names[0])
+		}
 	}
 
-	return true, nil
+	return names, nil
 }
 
-func IsToolboxImage(image string) (bool, error) {
-	info, err := Inspect("image", image)
-	if err != nil {
-		return false, fmt.Errorf("failed to inspect image %s", image)
+// GetContainerLabels returns the labels of a container, which is an empty map
+// if there are none.
+func GetContainerLabels(container string) (map[string]string, error) {
+	if _, err := ContainerExists(container); err != nil {
+		return nil, newWrappedError(ErrContainerNotFound, "container %s does not exist", container)
 	}
 
-	if info["Labels"] == nil {
-		return false, fmt.Errorf("%s is not a toolbox image", image)
+	info, err := Inspect("container", container)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s", container)
 	}
 
-	labels := info["Labels"].(map[string]interface{})
-	if labels["com.github.containers.toolbox"] != "true" && labels["com.github.debarshiray.toolbox"] != "true" {
-		return false, fmt.Errorf("%s is not a toolbox image", image)
+	labels := make(map[string]string)
+
+	config, _ := info["Config"].(map[string]interface{})
+	rawLabels, _ := config["Labels"].(map[string]interface{})
+	for key, value := range rawLabels {
+		if valueString, ok := value.(string); ok {
+			labels[key] = valueString
+		}
 	}
 
-	return true, nil
+	return labels, nil
 }
 
-// Pull pulls an image
-//
-// authfile is a path to a JSON authentication file and is internally used only
-// if it is not an empty string.
-func Pull(imageName string, authfile string) error {
-	logLevelString := LogLevel.String()
-	args := []string{"--log-level", logLevelString, "pull"}
-
-	if authfile != "" {
-		args = append(args, []string{"--authfile", authfile}...)
+// GetContainersByLabel returns all containers, running or not, that have the
+// label key set to value. If value is empty, all containers that have the
+// label key are returned, whatever its value. The returned slice is empty, but
+// not nil, if no container matches.
+func GetContainersByLabel(key, value string) ([]Container, error) {
+	containers, err := GetContainers("--all")
+	if err != nil {
+		return nil, err
 	}
 
-	args = append(args, imageName)
+	ret := []Container{}
 
-	if err := shell.Run("podman", nil, nil, nil, args...); err != nil {
-		return err
+	for _, container := range containers {
+		labelValue, ok := container.Labels[key]
+		if !ok {
+			continue
+		}
+
+		if value != "" && labelValue != value {
+			continue
+		}
+
+		ret = append(ret, container)
 	}
 
-	return nil
+	return ret, nil
 }
 
-func RemoveContainer(container string, forceDelete bool) error {
-	logrus.Debugf("Removing container %s", container)
+// GetContainersByStatus returns all containers whose status, as normalized by
+// NormalizeTaskState, matches the given one. Supported statuses are
+// "created", "exited", "paused" and "running". "stopped" is accepted as an
+// alias for "exited".
+func GetContainersByStatus(status string) ([]Container, error) {
+	switch status {
+	case "created", "exited", "paused", "running":
+	case "stopped":
+		status = "exited"
+	default:
+		return nil, fmt.Errorf("invalid container status %s", status)
+	}
 
-	logLevelString := LogLevel.String()
-	args := []string{"--log-level", logLevelString, "rm"}
+	containers, err := GetContainers("--all")
+	if err != nil {
+		return nil, err
+	}
 
-	if forceDelete {
-		args = append(args, "--force")
+	var ret []Container
+
+	for _, container := range containers {
+		if container.Status == status {
+			ret = append(ret, container)
+		}
 	}
 
-	args = append(args, container)
+	return ret, nil
+}
 
-	exitCode, err := shell.RunWithExitCode("podman", nil, nil, nil, args...)
+// GetContainerStatus returns the normalized status of a container as reported
+// by NormalizeTaskState.
+func GetContainerStatus(container string) (string, error) {
+	info, err := Inspect("container", container)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container %s", container)
+	}
+
+	state, _ := info["State"].(map[string]interface{})
+	status, _ := state["Status"].(string)
+	return NormalizeTaskState(status), nil
+}
+
+// GetDiskUsage returns the space used by images, containers and volumes, as
+// reported by 'podman system df'. Categories without anything in them use no
+// space.
+func GetDiskUsage() (DiskUsage, error) {
+	var stderr, stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "system", "df", "--format", "json"}
+
+	if err := run(context.Background(), nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		return DiskUsage{}, errorWithStderr(err, &stderr)
+	}
+
+	diskUsage, err := parseDiskUsage(stdout.Bytes())
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to parse the disk usage: %w", err)
+	}
+
+	return diskUsage, nil
+}
+
+// parseDiskUsage parses the output of 'podman system df --format json'. The
+// exact size is in RawSize, but older versions only have a human-readable
+// Size.
+func parseDiskUsage(data []byte) (DiskUsage, error) {
+	var raw []struct {
+		Type    string
+		RawSize *int64
+		Size    string
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return DiskUsage{}, err
+	}
+
+	var diskUsage DiskUsage
+
+	for _, category := range raw {
+		var size int64
+
+		if category.RawSize != nil {
+			size = *category.RawSize
+		} else if category.Size != "" {
+			var err error
+			size, err = units.FromHumanSize(category.Size)
+			if err != nil {
+				return DiskUsage{}, fmt.Errorf("invalid size %s", category.Size)
+			}
+		}
+
+		switch category.Type {
+		case "Images":
+			diskUsage.Images += size
+		case "Containers":
+			diskUsage.Containers += size
+		case "Local Volumes":
+			diskUsage.Volumes += size
+		}
+	}
+
+	return diskUsage, nil
+}
+
+// GetImagesDiskUsage returns the space used by each image, as reported by
+// 'podman system df --verbose'.
+func GetImagesDiskUsage() ([]ImageDiskUsage, error) {
+	var stderr, stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "system", "df", "--verbose"}
+
+	if err := run(context.Background(), nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		return nil, errorWithStderr(err, &stderr)
+	}
+
+	images, err := parseImagesDiskUsage(stdout.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the disk usage: %w", err)
+	}
+
+	return images, nil
+}
+
+// parseImagesDiskUsage parses the table of images in the output of 'podman
+// system df --verbose'. Podman doesn't support --format together with
+// --verbose, so the sizes are read back from their human-readable form, and
+// are only as precise as shown there. Columns are separated by at least two
+// spaces, because some cells, like CREATED, have single spaces in them.
+func parseImagesDiskUsage(output string) ([]ImageDiskUsage, error) {
+	separator := regexp.MustCompile(`\s{2,}`)
+
+	var columns map[string]int
+	var images []ImageDiskUsage
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if columns == nil {
+			if !strings.HasPrefix(line, "REPOSITORY") {
+				continue
+			}
+
+			columns = make(map[string]int)
+			for i, name := range separator.Split(line, -1) {
+				columns[name] = i
+			}
+
+			for _, name := range []string{"IMAGE ID", "SIZE", "SHARED SIZE", "UNIQUE SIZE", "CONTAINERS"} {
+				if _, ok := columns[name]; !ok {
+					return nil, fmt.Errorf("missing column %s", name)
+				}
+			}
+
+			continue
+		}
+
+		if line == "" {
+			break
+		}
+
+		cells := separator.Split(line, -1)
+		if len(cells) != len(columns) {
+			return nil, fmt.Errorf("invalid line %s", line)
+		}
+
+		var image ImageDiskUsage
+		image.ID = cells[columns["IMAGE ID"]]
+
+		sizes := []struct {
+			column string
+			size   *int64
+		}{
+			{"SIZE", &image.SizeBytes},
+			{"SHARED SIZE", &image.SharedBytes},
+			{"UNIQUE SIZE", &image.UniqueBytes},
+		}
+
+		for _, size := range sizes {
+			cell := cells[columns[size.column]]
+
+			var err error
+			*size.size, err = units.FromHumanSize(cell)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size %s", cell)
+			}
+		}
+
+		cell := cells[columns["CONTAINERS"]]
+
+		var err error
+		image.Containers, err = strconv.Atoi(cell)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of containers %s", cell)
+		}
+
+		images = append(images, image)
+	}
+
+	if columns == nil {
+		return nil, errors.New("missing table of images")
+	}
+
+	return images, nil
+}
+
+// GetImageDigest returns the digest of the manifest of an image.
+func GetImageDigest(image string) (string, error) {
+	info, err := Inspect("image", image)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s", image)
+	}
+
+	digest, _ := info["Digest"].(string)
+	return digest, nil
+}
+
+// GetImageLayerGraph maps the digests of the layers of all toolbox images to
+// the names of the images that use them, sorted by name. Layers used by more
+// than one image are stored only once, so this shows what the images share.
+// Images without names are named after their short IDs.
+func GetImageLayerGraph() (map[string][]string, error) {
+	images, err := GetImages()
+	if err != nil {
+		return nil, errors.New("failed to get images")
+	}
+
+	imageLayers := make(map[string][]Layer)
+	seen := make(map[string]bool)
+
+	for _, image := range images {
+		if !image.IsToolbox() || seen[image.ID] {
+			continue
+		}
+
+		seen[image.ID] = true
+
+		name := utils.ShortID(image.ID)
+		if len(image.Names) != 0 {
+			name = image.Names[0]
+		}
+
+		layers, err := GetImageLayers(image.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		imageLayers[name] = layers
+	}
+
+	graph := getLayerGraph(imageLayers)
+	return graph, nil
+}
+
+// getLayerGraph inverts a map from image names to their layers into one from
+// layer digests to the sorted names of the images that use them.
+func getLayerGraph(imageLayers map[string][]Layer) map[string][]string {
+	graph := make(map[string][]string)
+
+	for name, layers := range imageLayers {
+		for _, layer := range layers {
+			names := graph[layer.Digest]
+			if len(names) != 0 && names[len(names)-1] == name {
+				continue
+			}
+
+			graph[layer.Digest] = append(names, name)
+		}
+	}
+
+	for _, names := range graph {
+		sort.Strings(names)
+	}
+
+	return graph
+}
+
+// GetImageLayers returns the layers of an image in local storage, with the
+// base layer first. The digests are those of the uncompressed layers, and the
+// sizes are the space that they use on disk.
+func GetImageLayers(image string) ([]Layer, error) {
+	if _, err := ImageExists(image); err != nil {
+		return nil, newWrappedError(ErrImageNotFound, "image %s does not exist", image)
+	}
+
+	var stderr, stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "image", "inspect", "--format", "json", image}
+
+	if err := run(context.Background(), nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		err = errorWithStderr(err, &stderr)
+		return nil, fmt.Errorf("failed to inspect image %s: %w", image, err)
+	}
+
+	var info []struct {
+		RootFS struct {
+			Layers []string
+		}
+		History []struct {
+			EmptyLayer bool `json:"empty_layer"`
+		}
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		return nil, err
+	}
+
+	if len(info) != 1 {
+		return nil, fmt.Errorf("failed to inspect image %s", image)
+	}
+
+	stderr.Reset()
+	stdout.Reset()
+
+	args = []string{"--log-level", logLevelString, "history", "--format", "json", image}
+
+	if err := run(context.Background(), nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		err = errorWithStderr(err, &stderr)
+		return nil, fmt.Errorf("failed to get the history of image %s: %w", image, err)
+	}
+
+	var history []struct {
+		Size int64
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &history); err != nil {
+		return nil, err
+	}
+
+	historySizes := make([]int64, len(history))
+	for i, entry := range history {
+		historySizes[i] = entry.Size
+	}
+
+	emptyLayers := make([]bool, len(info[0].History))
+	for i, entry := range info[0].History {
+		emptyLayers[i] = entry.EmptyLayer
+	}
+
+	digests := info[0].RootFS.Layers
+	sizes := getLayerSizes(historySizes, emptyLayers, len(digests))
+
+	layers := make([]Layer, 0, len(digests))
+	for i, digest := range digests {
+		layers = append(layers, Layer{Digest: digest, SizeBytes: sizes[i]})
+	}
+
+	return layers, nil
+}
+
+// getLayerSizes picks the sizes of an image's count layers, base layer first,
+// out of the sizes of its history entries, as listed by 'podman history' with
+// the newest entry first. Entries that didn't add a layer, as marked by
+// emptyLayers in the opposite order of 'podman image inspect', are skipped. If
+// the history doesn't match the layers, the sizes are unknown and -1.
+func getLayerSizes(historySizes []int64, emptyLayers []bool, count int) []int64 {
+	var sizes []int64
+
+	for i := len(historySizes) - 1; i >= 0; i-- {
+		j := len(historySizes) - 1 - i
+		if len(emptyLayers) == len(historySizes) && emptyLayers[j] {
+			continue
+		}
+
+		sizes = append(sizes, historySizes[i])
+	}
+
+	if len(sizes) != count {
+		sizes = make([]int64, count)
+		for i := range sizes {
+			sizes[i] = -1
+		}
+	}
+
+	return sizes
+}
+
+// GetImageLabels returns the labels of an image, which is an empty map if
+// there are none.
+func GetImageLabels(image string) (map[string]string, error) {
+	if _, err := ImageExists(image); err != nil {
+		return nil, newWrappedError(ErrImageNotFound, "image %s does not exist", image)
+	}
+
+	info, err := Inspect("image", image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image %s", image)
+	}
+
+	labels := make(map[string]string)
+
+	rawLabels, _ := info["Labels"].(map[string]interface{})
+	for key, value := range rawLabels {
+		if valueString, ok := value.(string); ok {
+			labels[key] = valueString
+		}
+	}
+
+	return labels, nil
+}
+
+// GetImages is a wrapper function around `podman images --format json` command.
+//
+// Parameter args accepts an array of strings to be passed to the wrapped command (eg. ["-a", "--filter", "123"]).
+//
+// Returned value is a slice of Images.
+//
+// If a problem happens during execution, first argument is nil and second argument holds the error message.
+func GetImages(args ...string) ([]Image, error) {
+	return GetImagesContext(context.Background(), args...)
+}
+
+// GetImagesContext is like GetImages, but 'podman images' is killed and no
+// more attempts are made if ctx is done before it finishes. The returned error
+// then wraps ctx.Err().
+func GetImagesContext(ctx context.Context, args ...string) ([]Image, error) {
+	logLevelString := LogLevel.String()
+	args = append([]string{"--log-level", logLevelString, "images", "--format", "json"}, args...)
+
+	data, err := runListCommand(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []Image
+	if err := json.Unmarshal(data, &images); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// GetImagePlatforms fills in the Platform of the given images by inspecting
+// all of them at once.
+func GetImagePlatforms(images []Image) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+
+	for _, image := range images {
+		if !seen[image.ID] {
+			ids = append(ids, image.ID)
+			seen[image.ID] = true
+		}
+	}
+
+	var stderr, stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "image", "inspect", "--format", "json"}
+	args = append(args, ids...)
+
+	if err := run(context.Background(), nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	var info []struct {
+		ID           string `json:"Id"`
+		Os           string
+		Architecture string
+		Variant      string
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		return err
+	}
+
+	platforms := make(map[string]string)
+
+	for _, image := range info {
+		if platform := formatPlatform(image.Os, image.Architecture, image.Variant); platform != "" {
+			platforms[image.ID] = platform
+		}
+	}
+
+	for i := range images {
+		images[i].Platform = platforms[images[i].ID]
+	}
+
+	return nil
+}
+
+// GetImagePlatform returns the platform of an image in the same format as
+// GetImagePlatforms, or an empty string if it's unknown.
+func GetImagePlatform(image string) (string, error) {
+	info, err := Inspect("image", image)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s", image)
+	}
+
+	operatingSystem, _ := info["Os"].(string)
+	architecture, _ := info["Architecture"].(string)
+	variant, _ := info["Variant"].(string)
+
+	platform := formatPlatform(operatingSystem, architecture, variant)
+	return platform, nil
+}
+
+// formatPlatform joins the parts of a platform as os/architecture[/variant],
+// like Podman's --platform option expects. It returns an empty string if the
+// operating system or the architecture are unknown.
+func formatPlatform(operatingSystem, architecture, variant string) string {
+	if operatingSystem == "" || architecture == "" {
+		return ""
+	}
+
+	platform := operatingSystem + "/" + architecture
+	if variant != "" {
+		platform += "/" + variant
+	}
+
+	return platform
+}
+
+// FindMissingImages sets ImageMissing for the given containers whose images
+// aren't among images anymore, for example because they were removed with
+// 'podman rmi --force'. Images are matched by ID, because the name that a
+// container was created with may have been moved to another image or removed
+// while the image itself is still there. Podman V1 doesn't report the IDs of
+// the images of containers, so those are never marked as missing.
+func FindMissingImages(containers []Container, images []Image) {
+	exists := make(map[string]bool)
+	for _, image := range images {
+		exists[image.ID] = true
+	}
+
+	for i := range containers {
+		imageID := containers[i].ImageID
+		if imageID == "" {
+			continue
+		}
+
+		found := exists[imageID]
+		if !found {
+			logrus.Debugf("Image %s of container %s is missing", imageID, containers[i].ID)
+		}
+
+		containers[i].ImageMissing = !found
+	}
+}
+
+// GetImagesLargerThan returns the images whose size is more than the given
+// number of bytes, largest first. Images of unknown size are left out.
+func GetImagesLargerThan(bytes int64) ([]Image, error) {
+	images, err := GetImages()
+	if err != nil {
+		return nil, err
+	}
+
+	var largeImages []Image
+
+	for _, image := range images {
+		if image.SizeBytes >= 0 && image.SizeBytes > bytes {
+			largeImages = append(largeImages, image)
+		}
+	}
+
+	sort.SliceStable(largeImages, func(i, j int) bool {
+		if largeImages[i].SizeBytes != largeImages[j].SizeBytes {
+			return largeImages[i].SizeBytes > largeImages[j].SizeBytes
+		}
+
+		return largeImages[i].ID < largeImages[j].ID
+	})
+
+	return largeImages, nil
+}
+
+// GetVersion returns version of Podman in a string
+func GetVersion() (string, error) {
+	if podmanVersion != "" {
+		return podmanVersion, nil
+	}
+
+	var stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "version", "--format", "json"}
+
+	if err := run(context.Background(), nil, nil, &stdout, nil, args...); err != nil {
+		return "", err
+	}
+
+	if err := setVersion(stdout.Bytes()); err != nil {
+		return "", err
+	}
+
+	return podmanVersion, nil
+}
+
+func getEnterArgs(container, username, userShell string, tty bool) []string {
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "exec", "--interactive"}
+
+	if tty {
+		args = append(args, "--tty")
+	}
+
+	args = append(args, []string{
+		"--user", username,
+		container,
+		userShell, "-l",
+	}...)
+
+	return args
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// newWrappedError returns an error with the given message that wraps err.
+func newWrappedError(err error, format string, a ...interface{}) error {
+	msg := fmt.Sprintf(format, a...)
+	return &wrappedError{msg: msg, err: err}
+}
+
+func (err *wrappedError) Error() string {
+	return err.msg
+}
+
+func (err *wrappedError) Unwrap() error {
+	return err.err
+}
+
+// errorWithStderr adds the error messages that podman(1) printed on its
+// standard error to err. If there are lines starting with 'Error: ', only
+// those are used, because some commands also print their progress there.
+func errorWithStderr(err error, stderr *bytes.Buffer) error {
+	output := strings.TrimSpace(stderr.String())
+	if output == "" {
+		return err
+	}
+
+	var errorLines []string
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Error: ") {
+			line = strings.TrimPrefix(line, "Error: ")
+			errorLines = append(errorLines, line)
+		}
+	}
+
+	if len(errorLines) != 0 {
+		output = strings.Join(errorLines, "\n")
+	}
+
+	if strings.Contains(strings.ToLower(output), "permission denied") {
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, output)
+	}
+
+	return fmt.Errorf("%w: %s", err, output)
+}
+
+// isTransientError checks if the standard error of a failed podman(1) command
+// shows that it was caused by a concurrent operation, so that it might succeed
+// if it's run again.
+func isTransientError(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+
+	for _, transientError := range transientErrors {
+		if strings.Contains(stderr, transientError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getStderr returns a writer for the standard error of podman(1) that saves
+// it in stderr. When debugging, it's also copied to os.Stderr, like
+// shell.Run does when no writer is given.
+func getStderr(stderr *bytes.Buffer) io.Writer {
+	if logrus.GetLevel() >= logrus.DebugLevel {
+		return io.MultiWriter(stderr, os.Stderr)
+	}
+
+	return stderr
+}
+
+// hasToolboxLabel checks if labels mark a container or an image as compatible
+// with Toolbox.
+func hasToolboxLabel(labels map[string]string) bool {
+	return labels["com.github.containers.toolbox"] == "true" || labels["com.github.debarshiray.toolbox"] == "true"
+}
+
+// ImageExists checks using Podman if an image with given ID/name exists.
+//
+// Parameter image is a name or an id of an image.
+func ImageExists(image string) (bool, error) {
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "image", "exists", image}
+
+	exitCode, err := runWithExitCode(context.Background(), nil, nil, nil, getStderr(&stderr), args...)
+	if exitCode != 0 && err == nil {
+		err = fmt.Errorf("failed to find image %s", image)
+	}
+
+	if err != nil {
+		return false, errorWithStderr(err, &stderr)
+	}
+
+	return true, nil
+}
+
+// Inspect is a wrapper around 'podman inspect' command
+//
+// Parameter 'typearg' takes in values 'container' or 'image' that is passed to the --type flag
+func Inspect(typearg string, target string) (map[string]interface{}, error) {
+	var stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "inspect", "--format", "json", "--type", typearg, target}
+
+	if err := run(context.Background(), nil, nil, &stdout, nil, args...); err != nil {
+		return nil, err
+	}
+
+	output := stdout.Bytes()
+	var info []map[string]interface{}
+
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, err
+	}
+
+	return info[0], nil
+}
+
+func IsToolboxContainer(container string) (bool, error) {
+	info, err := Inspect("container", container)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect container %s", container)
+	}
+
+	labels, _ := info["Config"].(map[string]interface{})["Labels"].(map[string]interface{})
+	if labels["com.github.containers.toolbox"] != "true" && labels["com.github.debarshiray.toolbox"] != "true" {
+		return false, fmt.Errorf("%s is not a toolbox container", container)
+	}
+
+	return true, nil
+}
+
+func IsToolboxImage(image string) (bool, error) {
+	info, err := Inspect("image", image)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect image %s", image)
+	}
+
+	if info["Labels"] == nil {
+		return false, fmt.Errorf("%s is not a toolbox image", image)
+	}
+
+	labels := info["Labels"].(map[string]interface{})
+	if labels["com.github.containers.toolbox"] != "true" && labels["com.github.debarshiray.toolbox"] != "true" {
+		return false, fmt.Errorf("%s is not a toolbox image", image)
+	}
+
+	return true, nil
+}
+
+// NormalizeTaskState maps the state of a container, as reported by Podman or
+// containerd, to one of the canonical statuses: "created", "running",
+// "paused", "stopping", "removing", "exited" or "unknown". The last one is
+// what Podman reports for containers that are usually broken, so other
+// states, such as those added by newer versions, are kept in lower case
+// instead of being mistaken for it.
+//
+// Podman V1 reported a human-readable string in format "Up 5 minutes" or
+// "Exited (0) 5 minutes ago", while later versions and containerd report a
+// single word in lower or upper case.
+func NormalizeTaskState(raw string) string {
+	state := strings.ToLower(strings.TrimSpace(raw))
+
+	switch {
+	case state == "running" || strings.HasPrefix(state, "up "):
+		return "running"
+	case state == "created" || state == "configured" || state == "initialized":
+		return "created"
+	case state == "paused" || state == "pausing":
+		return "paused"
+	case state == "stopping":
+		return "stopping"
+	case state == "removing":
+		return "removing"
+	case state == "exited" || state == "stopped" || strings.HasPrefix(state, "exited "):
+		return "exited"
+	case state == "" || state == "unknown":
+		return "unknown"
+	}
+
+	return state
+}
+
+// Logs writes the output of a container to stdout and stderr, like
+// 'podman logs'.
+func Logs(ctx context.Context, container string, options LogsOptions, stdout, stderr io.Writer) error {
+	if options.Tail < 0 {
+		return fmt.Errorf("invalid number of lines %d", options.Tail)
+	}
+
+	if _, err := ContainerExists(container); err != nil {
+		return newWrappedError(ErrContainerNotFound, "container %s does not exist", container)
+	}
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "logs"}
+
+	if options.Follow {
+		args = append(args, "--follow")
+	}
+
+	if options.Tail != 0 {
+		args = append(args, "--tail", strconv.Itoa(options.Tail))
+	}
+
+	args = append(args, container)
+
+	if err := run(ctx, nil, nil, stdout, stderr, args...); err != nil {
+		return fmt.Errorf("failed to get the logs of container %s: %w", container, err)
+	}
+
+	return nil
+}
+
+// Pause freezes all the processes of a running container, until it's resumed.
+func Pause(container string) error {
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "pause", container}
+
+	if err := run(context.Background(), nil, nil, nil, getStderr(&stderr), args...); err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	return nil
+}
+
+// PauseAll pauses every running toolbox container. Like RemoveAllContainers,
+// it doesn't stop at the first failure. The names of the paused containers
+// are returned along with the errors for those that couldn't be paused, keyed
+// by name. The last return value is only set if the containers couldn't be
+// listed in the first place.
+func PauseAll() ([]string, map[string]error, error) {
+	return forAllContainersWithStatus("running", Pause)
+}
+
+// PingBackend checks that podman(1) can be found and run, so that callers can
+// fail early with a clear message instead of on every later command. Problems
+// reported by Podman itself are included in the error. If access to Podman's
+// files was denied, the error wraps ErrPermissionDenied and suggests how to
+// fix it.
+//
+// The version of Podman is cached along the way, so that GetVersion,
+// CheckVersion and EnsureSupportedBackend don't run Podman again. If it was
+// already cached, Podman is known to work and isn't run at all.
+func PingBackend(ctx context.Context) error {
+	if podmanVersion != "" {
+		return nil
+	}
+
+	var stderr bytes.Buffer
+	var stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "version", "--format", "json"}
+
+	if err := run(ctx, nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		var notFoundErr *shell.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return errors.New("podman(1) not found")
+		}
+
+		err = errorWithStderr(err, &stderr)
+		if errors.Is(err, ErrPermissionDenied) {
+			return fmt.Errorf("%w\n"+
+				"Run Toolbox as a regular user without sudo, and check that rootless Podman is set up "+
+				"for the user and that none of its files were created with sudo.", err)
+		}
+
+		return fmt.Errorf("podman(1) is not working: %w", err)
+	}
+
+	if err := setVersion(stdout.Bytes()); err != nil {
+		logrus.Debugf("Parsing the Podman version failed: %s", err)
+	}
+
+	return nil
+}
+
+// Pull pulls an image
+//
+// The fields of options are internally used only if they are not empty
+// strings, so the zero value keeps the default behaviour of Podman.
+//
+// If imageName is pinned to a digest, the digest of the pulled image is
+// verified against it.
+func Pull(imageName string, options PullOptions) error {
+	return PullContext(context.Background(), imageName, options)
+}
+
+// PullContext is like Pull, but 'podman pull' is killed if ctx is done before
+// it finishes, for example because the user pressed Ctrl+C. The returned error
+// then wraps ctx.Err().
+func PullContext(ctx context.Context, imageName string, options PullOptions) error {
+	env, err := getPullEnv(options)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "pull"}
+
+	authFile := options.AuthFile
+
+	if options.Username != "" {
+		if options.AuthFile != "" {
+			return errors.New("an authentication file and a user name cannot be used together")
+		}
+
+		authFile, err = writeAuthFile(imageName, options.Username, options.Password)
+		if err != nil {
+			return err
+		}
+
+		defer os.Remove(authFile)
+	}
+
+	if authFile != "" {
+		args = append(args, []string{"--authfile", authFile}...)
+	}
+
+	if options.Platform != "" {
+		args = append(args, []string{"--platform", options.Platform}...)
+	}
+
+	args = append(args, imageName)
+
+	stderrWriter := getStderr(&stderr)
+	if options.Progress != nil {
+		progressWriter := &pullProgressWriter{progress: options.Progress, seen: make(map[string]bool)}
+		stderrWriter = io.MultiWriter(stderrWriter, progressWriter)
+	}
+
+	if err := run(ctx, env, nil, nil, stderrWriter, args...); err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	imageRef, err := utils.ParseImageReference(imageName)
+	if err != nil || imageRef.Digest == "" {
+		return nil
+	}
+
+	if err := verifyImageDigest(imageName, imageRef.Digest, options.RemoveOnDigestMismatch); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PullIfMissing pulls an image only if it's not already present, and reports
+// whether it was pulled. Both the given name and its normalized form, like
+// docker.io/library/fedora:latest for fedora, are looked up, because Podman
+// may have stored a short name under either.
+func PullIfMissing(imageName string, options PullOptions) (bool, error) {
+	names := []string{imageName}
+
+	if imageRef, err := utils.ParseImageReference(imageName); err == nil {
+		if normalized := imageRef.String(); normalized != imageName {
+			names = append(names, normalized)
+		}
+	}
+
+	for _, name := range names {
+		if exists, _ := ImageExists(name); exists {
+			logrus.Debugf("Image %s is already present", name)
+			return false, nil
+		}
+	}
+
+	if err := Pull(imageName, options); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// verifyImageDigest checks that image has the given digest. The digest
+// requested for a manifest list differs from the digest of the image that was
+// actually stored, so the repository digests are consulted as well.
+func verifyImageDigest(image, digest string, removeOnMismatch bool) error {
+	imageDigest, err := GetImageDigest(image)
+	if err != nil {
+		return err
+	}
+
+	if imageDigest == digest {
+		return nil
+	}
+
+	info, err := Inspect("image", image)
+	if err != nil {
+		return fmt.Errorf("failed to inspect image %s", image)
+	}
+
+	repoDigests, _ := info["RepoDigests"].([]interface{})
+	for _, repoDigest := range repoDigests {
+		repoDigestString, _ := repoDigest.(string)
+		if strings.HasSuffix(repoDigestString, "@"+digest) {
+			return nil
+		}
+	}
+
+	if removeOnMismatch {
+		imageID, _ := info["Id"].(string)
+		if err := RemoveImage(imageID, true); err != nil {
+			logrus.Debugf("Removing image %s with mismatched digest failed: %s", image, err)
+		}
+	}
+
+	return fmt.Errorf("image %s has digest %s instead of %s", image, imageDigest, digest)
+}
+
+func (w *pullProgressWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+
+	for {
+		i := bytes.IndexByte(w.buffer, '\n')
+		if i == -1 {
+			break
+		}
+
+		line := string(w.buffer[:i])
+		w.buffer = w.buffer[i+1:]
+
+		if !strings.HasPrefix(line, "Copying blob ") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "Copying blob "))
+		if len(fields) == 0 || w.seen[fields[0]] {
+			continue
+		}
+
+		w.seen[fields[0]] = true
+
+		progress := PullProgress{
+			Digest:  fields[0],
+			Skipped: strings.Contains(line, "skipped"),
+		}
+
+		w.progress(progress)
+	}
+
+	return len(p), nil
+}
+
+func getPullEnv(options PullOptions) ([]string, error) {
+	if options.RegistriesConf == "" {
+		return nil, nil
+	}
+
+	if !utils.PathExists(options.RegistriesConf) {
+		return nil, fmt.Errorf("registries configuration %s not found", options.RegistriesConf)
+	}
+
+	env := []string{"CONTAINERS_REGISTRIES_CONF=" + options.RegistriesConf}
+	return env, nil
+}
+
+// writeAuthFile writes a temporary authentication file, in the format used by
+// 'podman login', with the credentials for the registry of image. The caller
+// must remove it.
+func writeAuthFile(image, username, password string) (string, error) {
+	domain := utils.ImageReferenceGetDomain(image)
+	if domain == "" {
+		return "", fmt.Errorf("failed to get the registry of image %s", image)
+	}
+
+	credentials := username + ":" + password
+
+	type auth struct {
+		Auth string `json:"auth"`
+	}
+
+	authConfig := struct {
+		Auths map[string]auth `json:"auths"`
+	}{
+		Auths: map[string]auth{
+			domain: {Auth: base64.StdEncoding.EncodeToString([]byte(credentials))},
+		},
+	}
+
+	data, err := json.Marshal(authConfig)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := ioutil.TempFile("", "toolbox-auth-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create authentication file: %w", err)
+	}
+
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write authentication file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+func RemoveContainer(container string, forceDelete bool) error {
+	logrus.Debugf("Removing container %s", container)
+
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "rm"}
+
+	if forceDelete {
+		args = append(args, "--force")
+	}
+
+	args = append(args, container)
+
+	exitCode, err := runWithExitCode(context.Background(), nil, nil, nil, getStderr(&stderr), args...)
 	switch exitCode {
 	case 0:
 		if err != nil {
 			panic("unexpected error: 'podman rm' finished successfully")
 		}
 	case 1:
-		err = fmt.Errorf("container %s does not exist", container)
+		err = newWrappedError(ErrContainerNotFound, "container %s does not exist", container)
 	case 2:
-		err = fmt.Errorf("container %s is running", container)
+		err = newWrappedError(ErrContainerRunning, "container %s is running", container)
 	default:
 		err = fmt.Errorf("failed to remove container %s", container)
 	}
 
+	if err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	return nil
+}
+
+// RemoveAllContainers removes all toolbox containers. Running and paused
+// containers are only removed if forceDelete is true.
+//
+// It doesn't stop at the first failure. The names of the removed containers
+// are returned along with the errors for those that couldn't be removed, keyed
+// by name. The last return value is only set if the containers couldn't be
+// listed in the first place.
+func RemoveAllContainers(forceDelete bool) ([]string, map[string]error, error) {
+	containers, err := GetContainers("--all")
+	if err != nil {
+		return nil, nil, errors.New("failed to get containers")
+	}
+
+	var names []string
+
+	for _, container := range containers {
+		if !container.IsToolbox() {
+			continue
+		}
+
+		name := container.ID
+		if len(container.Names) != 0 {
+			name = container.Names[0]
+		}
+
+		names = append(names, name)
+	}
+
+	removed, errs := RemoveContainers(names, forceDelete)
+	return removed, errs, nil
+}
+
+// RemoveContainers removes the given containers, for example those that the
+// user confirmed to remove. Like RemoveAllContainers, it doesn't stop at the
+// first failure, and returns the names of the removed containers along with
+// the errors for those that couldn't be removed.
+func RemoveContainers(containers []string, forceDelete bool) ([]string, map[string]error) {
+	var removed []string
+	errs := make(map[string]error)
+
+	for _, container := range containers {
+		if err := RemoveContainer(container, forceDelete); err != nil {
+			errs[container] = err
+			continue
+		}
+
+		removed = append(removed, container)
+	}
+
+	return removed, errs
+}
+
+// Resume unfreezes the processes of a paused container.
+func Resume(container string) error {
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "unpause", container}
+
+	if err := run(context.Background(), nil, nil, nil, getStderr(&stderr), args...); err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	return nil
+}
+
+// ResumeAll resumes every paused toolbox container, and reports the results
+// like PauseAll.
+func ResumeAll() ([]string, map[string]error, error) {
+	return forAllContainersWithStatus("paused", Resume)
+}
+
+// ensureContainerRunning fails if container isn't running.
+func ensureContainerRunning(container string) error {
+	running, err := TaskRunning(container)
 	if err != nil {
 		return err
 	}
 
+	if !running {
+		return fmt.Errorf("container %s is not running", container)
+	}
+
 	return nil
 }
 
+// forAllContainersWithStatus calls action on every toolbox container with the
+// given status, as explained for PauseAll.
+func forAllContainersWithStatus(status string, action func(string) error) ([]string, map[string]error, error) {
+	containers, err := GetContainersByStatus(status)
+	if err != nil {
+		return nil, nil, errors.New("failed to get containers")
+	}
+
+	var done []string
+	errs := make(map[string]error)
+
+	for _, container := range containers {
+		if !container.IsToolbox() {
+			continue
+		}
+
+		name := container.ID
+		if len(container.Names) != 0 {
+			name = container.Names[0]
+		}
+
+		if err := action(name); err != nil {
+			errs[name] = err
+			continue
+		}
+
+		done = append(done, name)
+	}
+
+	return done, errs, nil
+}
+
 func RemoveImage(image string, forceDelete bool) error {
 	logrus.Debugf("Removing image %s", image)
 
+	if !forceDelete {
+		containers, err := GetContainersUsingImage(image)
+		if err != nil {
+			return err
+		}
+
+		if len(containers) != 0 {
+			containersJoined := strings.Join(containers, ", ")
+			return newWrappedError(ErrImageInUse, "image %s is used by containers: %s", image, containersJoined)
+		}
+	}
+
+	return removeImage(image, forceDelete)
+}
+
+// removeImage is like RemoveImage, but without first checking whether any
+// containers use the image. Without forceDelete, 'podman rmi' still refuses
+// to remove an image that is used.
+func removeImage(image string, forceDelete bool) error {
+	var stderr bytes.Buffer
+
 	logLevelString := LogLevel.String()
 	args := []string{"--log-level", logLevelString, "rmi"}
 
@@ -378,42 +2088,195 @@ func RemoveImage(image string, forceDelete bool) error {
 
 	args = append(args, image)
 
-	exitCode, err := shell.RunWithExitCode("podman", nil, nil, nil, args...)
+	exitCode, err := runWithExitCode(context.Background(), nil, nil, nil, getStderr(&stderr), args...)
 	switch exitCode {
 	case 0:
 		if err != nil {
 			panic("unexpected error: 'podman rmi' finished successfully")
 		}
 	case 1:
-		err = fmt.Errorf("image %s does not exist", image)
+		err = newWrappedError(ErrImageNotFound, "image %s does not exist", image)
 	case 2:
-		err = fmt.Errorf("image %s has dependent children", image)
+		err = newWrappedError(ErrImageHasChildren, "image %s has dependent children", image)
 	default:
 		err = fmt.Errorf("failed to remove image %s", image)
 	}
 
 	if err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	return nil
+}
+
+// ResolveContainerID returns the full ID of the container with the given name
+// or ID prefix. It fails if none or more than one container match.
+func ResolveContainerID(name string) (string, error) {
+	containers, err := GetContainers("--all")
+	if err != nil {
+		return "", errors.New("failed to get containers")
+	}
+
+	var ids []string
+
+	for _, container := range containers {
+		matches := name != "" && strings.HasPrefix(container.ID, name)
+
+		for _, containerName := range container.Names {
+			if containerName == name {
+				matches = true
+				break
+			}
+		}
+
+		if matches {
+			ids = append(ids, container.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("container %s not found", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("container %s is ambiguous: it matches %d containers", name, len(ids))
+	}
+}
+
+// ResetVersionCache forgets the version of Podman found by GetVersion, so that
+// the next call asks Podman again.
+func ResetVersionCache() {
+	podmanVersion = ""
+}
+
+// setVersion caches the version of Podman from the output of 'podman version
+// --format json', so that GetVersion doesn't have to ask Podman again.
+func setVersion(output []byte) error {
+	var jsonoutput map[string]interface{}
+	if err := json.Unmarshal(output, &jsonoutput); err != nil {
 		return err
 	}
 
+	var ok bool
+
+	podmanClientInfoInterface := jsonoutput["Client"]
+	switch podmanClientInfo := podmanClientInfoInterface.(type) {
+	case nil:
+		podmanVersion, ok = jsonoutput["Version"].(string)
+	case map[string]interface{}:
+		podmanVersion, ok = podmanClientInfo["Version"].(string)
+	}
+
+	if !ok {
+		podmanVersion = ""
+		return errors.New("missing version")
+	}
+
+	return nil
+}
+
+// run runs podman(1) with the Runner set by SetRunner, and fails if its exit
+// code isn't 0, like shell.Run does.
+func run(ctx context.Context, env []string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	exitCode, err := runWithExitCode(ctx, env, stdin, stdout, stderr, args...)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return errors.New("failed to invoke podman(1)")
+	}
 	return nil
 }
 
+// runWithExitCode runs podman(1) with the Runner set by SetRunner, and returns
+// its exit code.
+func runWithExitCode(ctx context.Context,
+	env []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+	args ...string) (int, error) {
+	return runner.RunWithExitCode(ctx, "podman", env, stdin, stdout, stderr, args...)
+}
+
+// runListCommand runs a podman(1) command that lists containers or images and
+// returns its standard output. It's retried up to listRetries times if it
+// fails because of a concurrent operation, like a container being removed
+// while it was being listed. Other failures, like podman(1) not being
+// installed, are returned right away.
+func runListCommand(ctx context.Context, args []string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		var stderr, stdout bytes.Buffer
+
+		err := run(ctx, nil, nil, &stdout, getStderr(&stderr), args...)
+		if err == nil {
+			return stdout.Bytes(), nil
+		}
+
+		if attempt >= listRetries || !isTransientError(stderr.String()) {
+			return nil, errorWithStderr(err, &stderr)
+		}
+
+		logrus.Debugf("Listing failed because of a concurrent operation, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to invoke podman(1): %w", ctx.Err())
+		case <-time.After(listRetryDelay):
+		}
+	}
+}
+
 func SetLogLevel(logLevel logrus.Level) {
 	LogLevel = logLevel
 }
 
+// SetRunner makes the functions of this package run podman(1) with r, and
+// returns the Runner used so far. The default runs it with package os/exec.
+func SetRunner(r shell.Runner) shell.Runner {
+	previous := runner
+	runner = r
+	return previous
+}
+
 func Start(container string, stderr io.Writer) error {
 	logLevelString := LogLevel.String()
 	args := []string{"--log-level", logLevelString, "start", container}
 
-	if err := shell.Run("podman", nil, nil, stderr, args...); err != nil {
+	if err := run(context.Background(), nil, nil, nil, stderr, args...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Stop sends SIGTERM, or the stop signal of the container if it has a
+// different one, to the container's main process, and waits up to timeout
+// seconds for it to exit before sending SIGKILL. The stopped container can be
+// started again.
+func Stop(container string, timeout uint) error {
+	return StopContext(context.Background(), container, timeout)
+}
+
+// StopContext is like Stop, but 'podman stop' is killed if ctx is done before
+// it finishes. The returned error then wraps ctx.Err().
+func StopContext(ctx context.Context, container string, timeout uint) error {
+	logrus.Debugf("Stopping container %s with a timeout of %d seconds", container, timeout)
+
+	var stderr bytes.Buffer
+
+	timeoutString := strconv.FormatUint(uint64(timeout), 10)
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "stop", "--time", timeoutString, container}
+
+	if err := run(ctx, nil, nil, nil, getStderr(&stderr), args...); err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
+	return nil
+}
+
 func SystemMigrate(ociRuntimeRequired string) error {
 	logLevelString := LogLevel.String()
 	args := []string{"--log-level", logLevelString, "system", "migrate"}
@@ -421,9 +2284,73 @@ func SystemMigrate(ociRuntimeRequired string) error {
 		args = append(args, []string{"--new-runtime", ociRuntimeRequired}...)
 	}
 
-	if err := shell.Run("podman", nil, nil, nil, args...); err != nil {
+	if err := run(context.Background(), nil, nil, nil, nil, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TaskRunning returns whether the container's process is running. It is false
+// for a container that was created but never started, and for one that has
+// exited or is paused.
+func TaskRunning(container string) (bool, error) {
+	status, err := GetContainerStatus(container)
+	if err != nil {
+		return false, err
+	}
+
+	return status == "running", nil
+}
+
+// Top writes a table of the processes running in a container to stdout, like
+// 'podman top'. The descriptors select the columns, as explained in
+// podman-top(1), and Podman's default columns are used if there are none.
+func Top(container string, descriptors []string, stdout io.Writer) error {
+	if err := ensureContainerRunning(container); err != nil {
 		return err
 	}
 
+	var stderr bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "top", container}
+	args = append(args, descriptors...)
+
+	if err := run(context.Background(), nil, nil, stdout, getStderr(&stderr), args...); err != nil {
+		return errorWithStderr(err, &stderr)
+	}
+
 	return nil
 }
+
+// WaitTask blocks until the container stops and returns its exit code. It
+// returns immediately for a container that has already exited, and fails for
+// one that was never started, because it might never stop.
+func WaitTask(ctx context.Context, container string) (int, error) {
+	status, err := GetContainerStatus(container)
+	if err != nil {
+		return -1, err
+	}
+
+	if status == "created" {
+		return -1, fmt.Errorf("container %s was never started", container)
+	}
+
+	var stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "wait", container}
+
+	if err := run(ctx, nil, nil, &stdout, nil, args...); err != nil {
+		return -1, err
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	exitCode, err := strconv.Atoi(output)
+	if err != nil {
+		return -1, fmt.Errorf("failed to parse the exit code of container %s: %s", container, output)
+	}
+
+	return exitCode, nil
+}
@@ -0,0 +1,2323 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetContainersWithRunner(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "4c9f5ce5f4d4", "Names": ["fedora-toolbox-36"], "State": "running", "Created": 1600000000},
+  {"Id": "d5e0b4e3c6b1", "Names": ["gegl"], "State": "exited"}
+]`))
+
+	containers, err := GetContainers("--all")
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+
+	assert.Equal(t, "fedora-toolbox-36", containers[0].Names[0])
+	assert.Equal(t, "running", containers[0].Status)
+	assert.Equal(t, time.Unix(1600000000, 0), containers[0].CreatedAt)
+	assert.Equal(t, "exited", containers[1].Status)
+
+	assert.Equal(t, []string{"--log-level error ps --format json --all"}, r.Calls())
+}
+
+func TestGetImagesWithRunner(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "8b9affd1dbc2", "Names": ["registry.fedoraproject.org/fedora-toolbox:36"], "Size": 812345678},
+  {"Id": "d5e0b4e3c6b1", "Names": null}
+]`))
+
+	images, err := GetImages()
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+
+	assert.Equal(t, []string{"registry.fedoraproject.org/fedora-toolbox:36"}, images[0].Names)
+	assert.Equal(t, int64(812345678), images[0].SizeBytes)
+	assert.Empty(t, images[1].Names)
+
+	assert.Equal(t, []string{"--log-level error images --format json"}, r.Calls())
+}
+
+func TestRemoveImage(t *testing.T) {
+	testCases := []struct {
+		name       string
+		psOutput   string
+		errMsg     string
+		removeCall bool
+	}{
+		{
+			name:       "image not used by any container",
+			psOutput:   `[]`,
+			removeCall: true,
+		},
+		{
+			name:     "image used by containers",
+			psOutput: `[{"Names": ["fedora-toolbox-36"]}, {"Names": ["fedora-toolbox-gegl"]}]`,
+			errMsg: "image fedora-toolbox:36 is used by containers: " +
+				"fedora-toolbox-36, fedora-toolbox-gegl",
+			removeCall: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				if call.Arg(3) == "ps" {
+					fmt.Fprintln(call.Stdout, tc.psOutput)
+				}
+
+				return 0
+			})
+
+			err := RemoveImage("fedora-toolbox:36", false)
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.errMsg)
+			}
+
+			calls := r.Calls()
+			assert.Contains(t, calls[0], "ps --format json --all --filter ancestor=fedora-toolbox:36")
+
+			var removeCall bool
+			for _, call := range calls {
+				if strings.Contains(call, " rmi ") {
+					removeCall = true
+				}
+			}
+
+			assert.Equal(t, tc.removeCall, removeCall)
+		})
+	}
+}
+
+func TestRemoveErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		exitCode int
+		remove   func() error
+		errMsg   string
+		expected error
+	}{
+		{
+			name:     "container does not exist",
+			exitCode: 1,
+			remove: func() error {
+				return RemoveContainer("fedora-toolbox-36", false)
+			},
+			errMsg:   "container fedora-toolbox-36 does not exist",
+			expected: ErrContainerNotFound,
+		},
+		{
+			name:     "container is running",
+			exitCode: 2,
+			remove: func() error {
+				return RemoveContainer("fedora-toolbox-36", false)
+			},
+			errMsg:   "container fedora-toolbox-36 is running",
+			expected: ErrContainerRunning,
+		},
+		{
+			name:     "image does not exist",
+			exitCode: 1,
+			remove: func() error {
+				return RemoveImage("fedora-toolbox:36", true)
+			},
+			errMsg:   "image fedora-toolbox:36 does not exist",
+			expected: ErrImageNotFound,
+		},
+		{
+			name:     "image has dependent children",
+			exitCode: 2,
+			remove: func() error {
+				return RemoveImage("fedora-toolbox:36", true)
+			},
+			errMsg:   "image fedora-toolbox:36 has dependent children",
+			expected: ErrImageHasChildren,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, SetRunner, shelltest.Exit(tc.exitCode))
+
+			err := tc.remove()
+			assert.EqualError(t, err, tc.errMsg)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+
+	t.Run("image is used by containers", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			if call.Arg(3) != "ps" {
+				return 1
+			}
+
+			fmt.Fprintln(call.Stdout, `[{"Id": "4c9f5ce5f4d4", "Names": ["fedora-toolbox-36"]}]`)
+			return 0
+		})
+
+		err := RemoveImage("fedora-toolbox:36", false)
+		assert.EqualError(t, err, "image fedora-toolbox:36 is used by containers: fedora-toolbox-36")
+		assert.ErrorIs(t, err, ErrImageInUse)
+		assert.NotErrorIs(t, err, ErrImageHasChildren)
+		assert.Len(t, r.Calls(), 1)
+	})
+
+	t.Run("with standard error", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner,
+			shelltest.Fail("Error: cannot remove container fedora-toolbox-36 as it is running\n", 2))
+
+		err := RemoveContainer("fedora-toolbox-36", false)
+		assert.ErrorIs(t, err, ErrContainerRunning)
+		assert.NotErrorIs(t, err, ErrContainerNotFound)
+	})
+}
+
+func TestNormalizeTaskState(t *testing.T) {
+	testCases := []struct {
+		raw    string
+		status string
+	}{
+		{"CREATED", "created"},
+		{"RUNNING", "running"},
+		{"PAUSED", "paused"},
+		{"PAUSING", "paused"},
+		{"STOPPED", "exited"},
+		{"UNKNOWN", "unknown"},
+		{"created", "created"},
+		{"configured", "created"},
+		{"running", "running"},
+		{"paused", "paused"},
+		{"stopping", "stopping"},
+		{"removing", "removing"},
+		{"exited", "exited"},
+		{"Up 5 minutes", "running"},
+		{"Exited (0) 5 minutes ago", "exited"},
+		{"unknown", "unknown"},
+		{"", "unknown"},
+		{"Bogus", "bogus"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			status := NormalizeTaskState(tc.raw)
+			assert.Equal(t, tc.status, status)
+		})
+	}
+}
+
+func TestImageFlattenNames(t *testing.T) {
+	const id = "8b9affd1dbc261a7f586ed06a8fd993d09449a5ac79ebc7e80e86efdf3c223f6"
+
+	testCases := []struct {
+		name           string
+		names          []string
+		fillNameWithID bool
+		fullID         bool
+		expected       []string
+	}{
+		{
+			name:     "no names; not filled",
+			expected: []string{"<none>"},
+		},
+		{
+			name:           "no names; filled with short ID",
+			fillNameWithID: true,
+			expected:       []string{"8b9affd1dbc2"},
+		},
+		{
+			name:           "no names; filled with full ID",
+			fillNameWithID: true,
+			fullID:         true,
+			expected:       []string{id},
+		},
+		{
+			name:           "multiple names",
+			names:          []string{"fedora-toolbox:36", "localhost/gegl:latest"},
+			fillNameWithID: true,
+			expected:       []string{"fedora-toolbox:36", "localhost/gegl:latest"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			image := Image{ID: id, Names: tc.names}
+			flattenedImages := image.FlattenNames(tc.fillNameWithID, tc.fullID)
+
+			var names []string
+			for _, flattenedImage := range flattenedImages {
+				assert.Equal(t, id, flattenedImage.ID)
+				assert.Len(t, flattenedImage.Names, 1)
+				names = append(names, flattenedImage.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestImageSliceSort(t *testing.T) {
+	testCases := []struct {
+		name     string
+		images   ImageSlice
+		expected []string
+	}{
+		{
+			name: "flattened",
+			images: ImageSlice{
+				{ID: "2", Names: []string{"registry.fedoraproject.org/fedora-toolbox:37"}},
+				{ID: "1", Names: []string{"registry.fedoraproject.org/fedora-toolbox:36"}},
+			},
+			expected: []string{"1", "2"},
+		},
+		{
+			name: "unflattened",
+			images: ImageSlice{
+				{ID: "4", Names: []string{"localhost/foo", "localhost/bar"}},
+				{ID: "3"},
+				{ID: "2", Names: []string{"registry.fedoraproject.org/fedora-toolbox:37"}},
+				{ID: "1", Names: []string{"registry.fedoraproject.org/fedora-toolbox:36"}},
+			},
+			expected: []string{"1", "2", "3", "4"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				sort.Sort(tc.images)
+			})
+
+			ids := make([]string, 0, len(tc.images))
+			for _, image := range tc.images {
+				ids = append(ids, image.ID)
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestGetPullEnv(t *testing.T) {
+	registriesConf := filepath.Join(t.TempDir(), "registries.conf")
+	err := ioutil.WriteFile(registriesConf, []byte("unqualified-search-registries = []\n"), 0644)
+	require.NoError(t, err)
+
+	t.Run("not configured", func(t *testing.T) {
+		env, err := getPullEnv(PullOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, env)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		env, err := getPullEnv(PullOptions{RegistriesConf: registriesConf})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"CONTAINERS_REGISTRIES_CONF=" + registriesConf}, env)
+	})
+
+	t.Run("configured, but missing", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.conf")
+		_, err := getPullEnv(PullOptions{RegistriesConf: missing})
+		assert.EqualError(t, err, "registries configuration "+missing+" not found")
+	})
+}
+
+func TestGetContainersByLabel(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Names": ["gegl"], "Labels": {"com.github.containers.toolbox": "true", "project": "gegl"}},
+  {"Names": ["babl"], "Labels": {"com.github.containers.toolbox": "true", "project": "babl"}},
+  {"Names": ["empty"], "Labels": {"com.github.containers.toolbox": "true", "project": ""}},
+  {"Names": ["unlabelled"], "Labels": null}
+]`))
+
+	testCases := []struct {
+		name     string
+		key      string
+		value    string
+		expected []string
+	}{
+		{name: "key present", key: "project", expected: []string{"gegl", "babl", "empty"}},
+		{name: "key and value", key: "project", value: "babl", expected: []string{"babl"}},
+		{name: "no matching value", key: "project", value: "gimp", expected: []string{}},
+		{name: "no matching key", key: "owner", expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			containers, err := GetContainersByLabel(tc.key, tc.value)
+			require.NoError(t, err)
+			require.NotNil(t, containers)
+
+			names := []string{}
+			for _, container := range containers {
+				names = append(names, container.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestGetContainersByStatus(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Names": ["created"], "State": "created"},
+  {"Names": ["exited"], "State": "exited"},
+  {"Names": ["paused"], "State": "paused"},
+  {"Names": ["running"], "State": "running"},
+  {"Names": ["running-v1"], "State": 3, "Status": "Up 5 minutes"}
+]`))
+
+	testCases := []struct {
+		status   string
+		expected []string
+		errMsg   string
+	}{
+		{status: "created", expected: []string{"created"}},
+		{status: "exited", expected: []string{"exited"}},
+		{status: "stopped", expected: []string{"exited"}},
+		{status: "paused", expected: []string{"paused"}},
+		{status: "running", expected: []string{"running", "running-v1"}},
+		{status: "bogus", errMsg: "invalid container status bogus"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.status, func(t *testing.T) {
+			containers, err := GetContainersByStatus(tc.status)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			var names []string
+			for _, container := range containers {
+				names = append(names, container.Names[0])
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"Id": "4c9f5ce5f4d4", "Name": "fedora-toolbox-36"}]`))
+
+	info, err := Inspect("container", "fedora-toolbox-36")
+	assert.NoError(t, err)
+	assert.Equal(t, "fedora-toolbox-36", info["Name"])
+}
+
+func TestGetContainersContext(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Hang)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := GetContainersContext(ctx, "--all")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestGetImagesContextStopsRetrying(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner,
+		shelltest.Fail("Error: container 4c9f5ce5f4d4 does not exist in database: no such container\n", 125))
+
+	delay := listRetryDelay
+	listRetryDelay = 10 * time.Second
+	t.Cleanup(func() {
+		listRetryDelay = delay
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := GetImagesContext(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	assert.Len(t, r.Calls(), 1)
+}
+
+func TestPullContext(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Hang)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := PullContext(ctx, "registry.fedoraproject.org/fedora-toolbox:36", PullOptions{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestPullProgress(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		fmt.Fprint(call.Stderr, `Trying to pull registry.fedoraproject.org/fedora-toolbox:36...
+Getting image source signatures
+Copying blob sha256:3f7ad2e8c6b1 skipped: already exists
+Copying blob sha256:5a1e8d4f2b1c
+Copying blob sha256:9d0c7b6a5e4f
+Copying blob sha256:5a1e8d4f2b1c done
+Copying config sha256:8b9affd1dbc2 done
+Writing manifest to image destination
+Storing signatures
+`)
+		fmt.Fprintln(call.Stdout, "8b9affd1dbc2")
+		return 0
+	})
+
+	var progress []PullProgress
+	options := PullOptions{
+		Progress: func(p PullProgress) {
+			progress = append(progress, p)
+		},
+	}
+
+	err := Pull("registry.fedoraproject.org/fedora-toolbox:36", options)
+	require.NoError(t, err)
+
+	expected := []PullProgress{
+		{Digest: "sha256:3f7ad2e8c6b1", Skipped: true},
+		{Digest: "sha256:5a1e8d4f2b1c"},
+		{Digest: "sha256:9d0c7b6a5e4f"},
+	}
+
+	assert.Equal(t, expected, progress)
+}
+
+func TestPullCredentials(t *testing.T) {
+	var authFile []byte
+
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		for i, arg := range call.Args {
+			if arg == "--authfile" {
+				var err error
+				authFile, err = ioutil.ReadFile(call.Args[i+1])
+				require.NoError(t, err)
+			}
+		}
+
+		return 0
+	})
+
+	options := PullOptions{Username: "gegl", Password: "hunter2"}
+	err := Pull("registry.example.com/gegl/toolbox:latest", options)
+	require.NoError(t, err)
+
+	calls := r.Calls()
+	require.Len(t, calls, 1)
+	assert.NotContains(t, calls[0], "hunter2")
+
+	fields := strings.Fields(calls[0])
+	require.Len(t, fields, 6)
+	assert.Equal(t, "--authfile", fields[3])
+	assert.NoFileExists(t, fields[4], "temporary authentication file")
+
+	expected := `{"auths":{"registry.example.com":{"auth":"Z2VnbDpodW50ZXIy"}}}`
+	assert.JSONEq(t, expected, string(authFile))
+
+	t.Run("with authentication file", func(t *testing.T) {
+		options := PullOptions{AuthFile: "/tmp/auth.json", Username: "gegl", Password: "hunter2"}
+		err := Pull("registry.example.com/gegl/toolbox:latest", options)
+		assert.EqualError(t, err, "an authentication file and a user name cannot be used together")
+	})
+}
+
+func TestContainerUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      string
+		expected  Container
+		createdAt time.Time
+	}{
+		{
+			name: "Podman V1",
+			data: `{
+  "ID": "4c9f5ce5f4d4",
+  "Names": "fedora-toolbox-30",
+  "Status": "Up 5 minutes ago",
+  "State": 3,
+  "Created": "5 minutes ago",
+  "Image": "registry.fedoraproject.org/f30/fedora-toolbox:30",
+  "Command": "toolbox --verbose init-container",
+  "Labels": {"com.github.containers.toolbox": "true"},
+  "Pid": 4242
+}`,
+			expected: Container{
+				ID:        "4c9f5ce5f4d4",
+				Names:     []string{"fedora-toolbox-30"},
+				Status:    "running",
+				Created:   "5 minutes ago",
+				Image:     "registry.fedoraproject.org/f30/fedora-toolbox:30",
+				Command:   []string{"toolbox", "--verbose", "init-container"},
+				Labels:    map[string]string{"com.github.containers.toolbox": "true"},
+				Pid:       4242,
+				SizeBytes: -1,
+			},
+		},
+		{
+			name: "Podman V2",
+			data: `{
+  "Id": "4c9f5ce5f4d4",
+  "Names": ["fedora-toolbox-36", "fedora-toolbox-36-alias"],
+  "State": "exited",
+  "Created": 1600000000,
+  "Image": "registry.fedoraproject.org/fedora-toolbox:36",
+  "Command": ["toolbox", "--log-level", "debug", "init-container", "--home", "/home/user"],
+  "Labels": {"com.github.containers.toolbox": "true"},
+  "Pid": 0,
+  "ExitCode": 1,
+  "StartedAt": 1600000100,
+  "ExitedAt": 1600000200,
+  "Size": {"rwSize": 40960, "rootFsSize": 812345678}
+}`,
+			expected: Container{
+				ID:        "4c9f5ce5f4d4",
+				Names:     []string{"fedora-toolbox-36", "fedora-toolbox-36-alias"},
+				Status:    "exited",
+				CreatedAt: time.Unix(1600000000, 0),
+				Image:     "registry.fedoraproject.org/fedora-toolbox:36",
+				Command: []string{
+					"toolbox", "--log-level", "debug", "init-container", "--home", "/home/user",
+				},
+				Labels:    map[string]string{"com.github.containers.toolbox": "true"},
+				ExitCode:  1,
+				StartedAt: time.Unix(1600000100, 0),
+				ExitedAt:  time.Unix(1600000200, 0),
+				SizeBytes: 40960,
+			},
+		},
+		{
+			name: "Podman V1 exited",
+			data: `{
+  "ID": "4c9f5ce5f4d4",
+  "Names": "fedora-toolbox-30",
+  "Status": "Exited (137) 5 minutes ago",
+  "State": 5,
+  "Created": "1 hour ago"
+}`,
+			expected: Container{
+				ID:        "4c9f5ce5f4d4",
+				Names:     []string{"fedora-toolbox-30"},
+				Status:    "exited",
+				Created:   "1 hour ago",
+				ExitCode:  137,
+				SizeBytes: -1,
+			},
+		},
+		{
+			name: "no command",
+			data: `{"Id": "4c9f5ce5f4d4", "Names": ["gegl"], "State": "created", "StartedAt": -62135596800}`,
+			expected: Container{
+				ID:        "4c9f5ce5f4d4",
+				Names:     []string{"gegl"},
+				Status:    "created",
+				SizeBytes: -1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var container Container
+			err := json.Unmarshal([]byte(tc.data), &container)
+			assert.NoError(t, err)
+
+			if !tc.expected.CreatedAt.IsZero() {
+				assert.NotEmpty(t, container.Created)
+				tc.expected.Created = container.Created
+			}
+
+			assert.Equal(t, tc.expected, container)
+		})
+	}
+}
+
+func TestGetContainersStatus(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Names": ["never-started"], "State": "created"},
+  {"Names": ["never-started-configured"], "State": "configured"},
+  {"Names": ["running"], "State": "running"},
+  {"Names": ["exited"], "State": "exited"},
+  {"Names": ["stopped"], "State": "stopped"},
+  {"Names": ["never-started-v1"], "State": 1, "Status": "Created"},
+  {"Names": ["exited-v1"], "State": 5, "Status": "Exited (0) 2 hours ago"}
+]`))
+
+	containers, err := GetContainers("--all")
+	assert.NoError(t, err)
+
+	statuses := make(map[string]string)
+	for _, container := range containers {
+		statuses[container.Names[0]] = container.Status
+	}
+
+	expected := map[string]string{
+		"never-started":            "created",
+		"never-started-configured": "created",
+		"running":                  "running",
+		"exited":                   "exited",
+		"stopped":                  "exited",
+		"never-started-v1":         "created",
+		"exited-v1":                "exited",
+	}
+
+	assert.Equal(t, expected, statuses)
+}
+
+func TestRemoveAllContainers(t *testing.T) {
+	fakePodman := func(call shelltest.Call) int {
+		switch call.Arg(3) {
+		case "ps":
+			fmt.Fprintln(call.Stdout, `[
+  {"Names": ["running"], "State": "running", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["exited"], "State": "exited", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["legacy"], "State": "exited", "Labels": {"com.github.debarshiray.toolbox": "true"}},
+  {"Names": ["not-toolbox"], "State": "exited", "Labels": {}}
+]`)
+		case "rm":
+			if call.Arg(4) == "running" {
+				return 2
+			}
+		}
+
+		return 0
+	}
+
+	t.Run("not forced", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, fakePodman)
+
+		removed, errs, err := RemoveAllContainers(false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"exited", "legacy"}, removed)
+		assert.Len(t, errs, 1)
+		assert.EqualError(t, errs["running"], "container running is running")
+
+		calls := r.Calls()
+		assert.NotContains(t, calls, "--log-level error rm not-toolbox")
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, fakePodman)
+
+		removed, errs, err := RemoveAllContainers(true)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"running", "exited", "legacy"}, removed)
+		assert.Empty(t, errs)
+
+		calls := r.Calls()
+		assert.Contains(t, calls, "--log-level error rm --force running")
+	})
+}
+
+func TestRemoveContainers(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		if call.Arg(4) == "running" {
+			return 2
+		}
+
+		return 0
+	})
+
+	removed, errs := RemoveContainers([]string{"running", "exited"}, false)
+	assert.Equal(t, []string{"exited"}, removed)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["running"], ErrContainerRunning)
+
+	calls := r.Calls()
+	assert.Equal(t, []string{"--log-level error rm running", "--log-level error rm exited"}, calls)
+}
+
+func TestPauseAllResumeAll(t *testing.T) {
+	fakePodman := func(call shelltest.Call) int {
+		switch call.Arg(3) {
+		case "ps":
+			fmt.Fprintln(call.Stdout, `[
+  {"Names": ["running"], "State": "running", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["running-v1"], "State": 3, "Status": "Up 5 minutes",
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["stuck"], "State": "running", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["exited"], "State": "exited", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["paused"], "State": "paused", "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Names": ["not-toolbox"], "State": "running", "Labels": {}}
+]`)
+		case "pause", "unpause":
+			if call.Arg(4) == "stuck" {
+				fmt.Fprintf(call.Stderr, "Error: %s cannot be paused: cgroup freezer not supported\n", call.Arg(4))
+				return 125
+			}
+		}
+
+		return 0
+	}
+
+	t.Run("pause", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, fakePodman)
+
+		paused, errs, err := PauseAll()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"running", "running-v1"}, paused)
+		assert.Len(t, errs, 1)
+		assert.EqualError(t, errs["stuck"],
+			"failed to invoke podman(1): stuck cannot be paused: cgroup freezer not supported")
+
+		calls := r.Calls()
+		assert.NotContains(t, calls, "--log-level error pause exited")
+		assert.NotContains(t, calls, "--log-level error pause paused")
+		assert.NotContains(t, calls, "--log-level error pause not-toolbox")
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, fakePodman)
+
+		resumed, errs, err := ResumeAll()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"paused"}, resumed)
+		assert.Empty(t, errs)
+
+		calls := r.Calls()
+		assert.Contains(t, calls, "--log-level error unpause paused")
+	})
+}
+
+func TestGetImageDigest(t *testing.T) {
+	const digest = "sha256:8b9affd1dbc261a7f586ed06a8fd993d09449a5ac79ebc7e80e86efdf3c223f6"
+
+	t.Run("present image", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"Id": "9c0b0ee2ecd3", "Digest": "`+digest+`"}]`))
+
+		imageDigest, err := GetImageDigest("fedora-toolbox:36")
+		assert.NoError(t, err)
+		assert.Equal(t, digest, imageDigest)
+		assert.Equal(t,
+			[]string{"--log-level error inspect --format json --type image fedora-toolbox:36"},
+			r.Calls())
+	})
+
+	t.Run("missing image", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Exit(1))
+
+		_, err := GetImageDigest("fedora-toolbox:36")
+		assert.EqualError(t, err, "failed to inspect image fedora-toolbox:36")
+	})
+}
+
+func TestEnter(t *testing.T) {
+	userShell := os.Getenv("SHELL")
+	os.Setenv("SHELL", "/bin/zsh")
+	t.Cleanup(func() {
+		os.Setenv("SHELL", userShell)
+	})
+
+	currentUser, err := user.Current()
+	require.NoError(t, err)
+
+	enterCall := "--log-level error exec --interactive --user " + currentUser.Username +
+		" fedora-toolbox-36 /bin/zsh -l"
+
+	testCases := []struct {
+		name     string
+		status   string
+		expected []string
+	}{
+		{
+			name:   "running container",
+			status: "running",
+			expected: []string{
+				"--log-level error inspect --format json --type container fedora-toolbox-36",
+				enterCall,
+			},
+		},
+		{
+			name:   "exited container",
+			status: "exited",
+			expected: []string{
+				"--log-level error inspect --format json --type container fedora-toolbox-36",
+				"--log-level error start fedora-toolbox-36",
+				enterCall,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				if call.Arg(3) == "inspect" {
+					fmt.Fprintln(call.Stdout, `[{"State": {"Status": "`+tc.status+`"}}]`)
+				}
+
+				return 0
+			})
+
+			var stdout, stderr bytes.Buffer
+			err := Enter("fedora-toolbox-36", strings.NewReader(""), &stdout, &stderr)
+			assert.NoError(t, err)
+
+			calls := r.Calls()
+			assert.Equal(t, tc.expected, calls)
+		})
+	}
+}
+
+func TestGetEnterArgs(t *testing.T) {
+	args := getEnterArgs("fedora-toolbox-36", "user", "/bin/bash", true)
+	assert.Equal(t, []string{
+		"--log-level", "error",
+		"exec",
+		"--interactive",
+		"--tty",
+		"--user", "user",
+		"fedora-toolbox-36",
+		"/bin/bash", "-l",
+	}, args)
+}
+
+func TestPullVerifyDigest(t *testing.T) {
+	const digest = "sha256:8b9affd1dbc261a7f586ed06a8fd993d09449a5ac79ebc7e80e86efdf3c223f6"
+	const otherDigest = "sha256:4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01"
+
+	testCases := []struct {
+		name        string
+		image       string
+		imageDigest string
+		remove      bool
+		errMsg      string
+		inspectCall bool
+		removeCall  bool
+	}{
+		{
+			name:        "digest matches",
+			image:       "registry.fedoraproject.org/fedora-toolbox@" + digest,
+			imageDigest: digest,
+			inspectCall: true,
+		},
+		{
+			name:        "digest mismatches",
+			image:       "registry.fedoraproject.org/fedora-toolbox@" + digest,
+			imageDigest: otherDigest,
+			errMsg: "image registry.fedoraproject.org/fedora-toolbox@" + digest +
+				" has digest " + otherDigest + " instead of " + digest,
+			inspectCall: true,
+		},
+		{
+			name:        "digest mismatches; removed",
+			image:       "registry.fedoraproject.org/fedora-toolbox@" + digest,
+			imageDigest: otherDigest,
+			remove:      true,
+			errMsg: "image registry.fedoraproject.org/fedora-toolbox@" + digest +
+				" has digest " + otherDigest + " instead of " + digest,
+			inspectCall: true,
+			removeCall:  true,
+		},
+		{
+			name:        "tag only",
+			image:       "registry.fedoraproject.org/fedora-toolbox:37",
+			imageDigest: otherDigest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				if call.Arg(3) == "inspect" {
+					fmt.Fprintln(call.Stdout, `[{"Id": "9c0b0ee2ecd3", "Digest": "`+tc.imageDigest+`", "RepoDigests": []}]`)
+				}
+
+				return 0
+			})
+
+			err := Pull(tc.image, PullOptions{RemoveOnDigestMismatch: tc.remove})
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.errMsg)
+			}
+
+			var inspectCall, removeCall bool
+			for _, call := range r.Calls() {
+				if strings.Contains(call, " inspect ") {
+					inspectCall = true
+				}
+				if strings.Contains(call, " rmi --force 9c0b0ee2ecd3") {
+					removeCall = true
+				}
+			}
+
+			assert.Equal(t, tc.inspectCall, inspectCall)
+			assert.Equal(t, tc.removeCall, removeCall)
+		})
+	}
+}
+
+func TestGetImagesLabels(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "8b9affd1dbc2", "Names": ["registry.fedoraproject.org/fedora-toolbox:36"],
+   "Labels": {"com.github.containers.toolbox": "true", "version": "36"}},
+  {"Id": "e6f1c5f4d7c2", "Names": ["registry.fedoraproject.org/fedora:36"],
+   "Labels": {"license": "MIT"}},
+  {"Id": "d5e0b4e3c6b1", "Names": ["docker.io/library/alpine:latest"], "Labels": null}
+]`))
+
+	images, err := GetImages()
+	require.NoError(t, err)
+	require.Len(t, images, 3)
+
+	assert.Equal(t, map[string]string{"com.github.containers.toolbox": "true", "version": "36"}, images[0].Labels)
+	assert.Equal(t, map[string]string{"license": "MIT"}, images[1].Labels)
+	assert.Empty(t, images[2].Labels)
+
+	assert.True(t, images[0].IsToolbox())
+	assert.False(t, images[1].IsToolbox())
+	assert.False(t, images[2].IsToolbox())
+}
+
+func TestIsToolbox(t *testing.T) {
+	testCases := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			name:     "current label",
+			labels:   map[string]string{"com.github.containers.toolbox": "true"},
+			expected: true,
+		},
+		{
+			name:     "legacy label",
+			labels:   map[string]string{"com.github.debarshiray.toolbox": "true"},
+			expected: true,
+		},
+		{
+			name:   "label not set to true",
+			labels: map[string]string{"com.github.containers.toolbox": "false"},
+		},
+		{
+			name:   "other labels",
+			labels: map[string]string{"license": "MIT"},
+		},
+		{
+			name: "no labels",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			image := Image{ID: "8b9affd1dbc2", Labels: tc.labels}
+			assert.Equal(t, tc.expected, image.IsToolbox())
+
+			container := Container{ID: "4c9f5ce5f4d4", Labels: tc.labels}
+			assert.Equal(t, tc.expected, container.IsToolbox())
+		})
+	}
+}
+
+func TestTaskRunning(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected bool
+		errMsg   string
+	}{
+		{
+			name:     "running",
+			output:   `[{"State": {"Status": "running"}}]`,
+			expected: true,
+		},
+		{
+			name:   "stopped",
+			output: `[{"State": {"Status": "exited"}}]`,
+		},
+		{
+			name:   "paused",
+			output: `[{"State": {"Status": "paused"}}]`,
+		},
+		{
+			name:   "never started",
+			output: `[{"State": {"Status": "configured"}}]`,
+		},
+		{
+			name:   "non-existent",
+			errMsg: "failed to inspect container fedora-toolbox-36",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				if tc.output == "" {
+					fmt.Fprintln(call.Stderr, "Error: no such container fedora-toolbox-36")
+					return 125
+				}
+
+				fmt.Fprintln(call.Stdout, tc.output)
+				return 0
+			})
+
+			running, err := TaskRunning("fedora-toolbox-36")
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, running)
+		})
+	}
+}
+
+func TestWaitTask(t *testing.T) {
+	t.Run("non-zero exit code", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			switch call.Arg(3) {
+			case "inspect":
+				fmt.Fprintln(call.Stdout, `[{"State": {"Status": "running"}}]`)
+			case "wait":
+				fmt.Fprintln(call.Stdout, "42")
+			}
+
+			return 0
+		})
+
+		exitCode, err := WaitTask(context.Background(), "fedora-toolbox-36")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, exitCode)
+
+		calls := r.Calls()
+		assert.Contains(t, calls, "--log-level error wait fedora-toolbox-36")
+	})
+
+	t.Run("never started", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"State": {"Status": "created"}}]`))
+
+		_, err := WaitTask(context.Background(), "fedora-toolbox-36")
+		assert.EqualError(t, err, "container fedora-toolbox-36 was never started")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			if call.Arg(3) == "wait" {
+				return shelltest.Hang(call)
+			}
+
+			fmt.Fprintln(call.Stdout, `[{"State": {"Status": "running"}}]`)
+			return 0
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := WaitTask(ctx, "fedora-toolbox-36")
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+}
+
+func TestEnsureSupportedBackend(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version string
+		errMsg  string
+	}{
+		{
+			name:    "supported",
+			version: "4.2.0",
+		},
+		{
+			name:    "minimum",
+			version: "1.4.0",
+		},
+		{
+			name:    "unsupported",
+			version: "1.3.1",
+			errMsg:  "Podman 1.3.1 is not supported\nUpgrade to Podman 1.4.0 or newer.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ResetVersionCache()
+			t.Cleanup(ResetVersionCache)
+
+			shelltest.SetUp(t, SetRunner, shelltest.Output(`{"Client": {"Version": "`+tc.version+`"}}`))
+
+			err := EnsureSupportedBackend()
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestGetImageLayerGraph(t *testing.T) {
+	layers := map[string]string{
+		"8b9affd1dbc2": `"sha256:base", "sha256:toolbox"`,
+		"9c0b0ee2ecd3": `"sha256:base", "sha256:toolbox", "sha256:gegl"`,
+		"e6f1c5f4d7c2": `"sha256:other"`,
+	}
+
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		switch call.Arg(3) + " " + call.Arg(4) {
+		case "images --format":
+			fmt.Fprintln(call.Stdout, `[
+  {"Id": "8b9affd1dbc2", "Names": ["fedora-toolbox:36"], "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "9c0b0ee2ecd3", "Names": ["fedora-toolbox-gegl:36"], "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "e6f1c5f4d7c2", "Names": null, "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "d5e0b4e3c6b1", "Names": ["alpine:latest"], "Labels": null}
+]`)
+		case "image inspect":
+			fmt.Fprintf(call.Stdout, `[{"RootFS": {"Layers": [%s]}}]`+"\n", layers[call.Arg(7)])
+		case "history --format":
+			fmt.Fprintln(call.Stdout, "[]")
+		}
+
+		return 0
+	})
+
+	graph, err := GetImageLayerGraph()
+	require.NoError(t, err)
+
+	expected := map[string][]string{
+		"sha256:base":    {"fedora-toolbox-gegl:36", "fedora-toolbox:36"},
+		"sha256:toolbox": {"fedora-toolbox-gegl:36", "fedora-toolbox:36"},
+		"sha256:gegl":    {"fedora-toolbox-gegl:36"},
+		"sha256:other":   {"e6f1c5f4d7c2"},
+	}
+
+	assert.Equal(t, expected, graph)
+
+	var inspectCalls int
+	for _, call := range r.Calls() {
+		if strings.HasPrefix(call, "--log-level error image inspect") {
+			inspectCalls++
+		}
+	}
+
+	assert.Equal(t, 3, inspectCalls)
+}
+
+func TestGetLayerGraphRepeatedLayer(t *testing.T) {
+	imageLayers := map[string][]Layer{
+		"fedora-toolbox:36": {{Digest: "sha256:base"}, {Digest: "sha256:empty"}, {Digest: "sha256:empty"}},
+	}
+
+	expected := map[string][]string{
+		"sha256:base":  {"fedora-toolbox:36"},
+		"sha256:empty": {"fedora-toolbox:36"},
+	}
+
+	assert.Equal(t, expected, getLayerGraph(imageLayers))
+}
+
+func TestGetImageLayers(t *testing.T) {
+	t.Run("multiple layers", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			switch call.Arg(3) {
+			case "image":
+				if call.Arg(4) == "exists" {
+					return 0
+				}
+
+				fmt.Fprintln(call.Stdout, `[{
+  "RootFS": {"Type": "layers", "Layers": ["sha256:2a0fc6bf62e1", "sha256:8c5f4d2e9b3a", "sha256:d41d8cd98f00"]},
+  "History": [
+    {"created_by": "/bin/sh -c #(nop) ADD file:base in /"},
+    {"created_by": "/bin/sh -c #(nop) LABEL com.github.containers.toolbox=true", "empty_layer": true},
+    {"created_by": "/bin/sh -c dnf install -y toolbox"},
+    {"created_by": "/bin/sh -c touch /etc/toolbox"}
+  ]
+}]`)
+			case "history":
+				fmt.Fprintln(call.Stdout, `[
+  {"id": "8b9affd1dbc2", "size": 32},
+  {"id": "<missing>", "size": 724531200},
+  {"id": "<missing>", "size": 0},
+  {"id": "<missing>", "size": 190054400}
+]`)
+			}
+
+			return 0
+		})
+
+		layers, err := GetImageLayers("fedora-toolbox:36")
+		require.NoError(t, err)
+
+		expected := []Layer{
+			{Digest: "sha256:2a0fc6bf62e1", SizeBytes: 190054400},
+			{Digest: "sha256:8c5f4d2e9b3a", SizeBytes: 724531200},
+			{Digest: "sha256:d41d8cd98f00", SizeBytes: 32},
+		}
+
+		assert.Equal(t, expected, layers)
+
+		var total int64
+		for _, layer := range layers {
+			total += layer.SizeBytes
+		}
+
+		assert.Equal(t, int64(914585632), total)
+
+		calls := r.Calls()
+		assert.Equal(t, []string{
+			"--log-level error image exists fedora-toolbox:36",
+			"--log-level error image inspect --format json fedora-toolbox:36",
+			"--log-level error history --format json fedora-toolbox:36",
+		}, calls)
+	})
+
+	t.Run("non-existent", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, shelltest.Exit(1))
+
+		_, err := GetImageLayers("fedora-toolbox:36")
+		assert.EqualError(t, err, "image fedora-toolbox:36 does not exist")
+		assert.True(t, errors.Is(err, ErrImageNotFound))
+		assert.Len(t, r.Calls(), 1)
+	})
+}
+
+func TestGetLayerSizes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		historySizes []int64
+		emptyLayers  []bool
+		count        int
+		expected     []int64
+	}{
+		{
+			name:         "empty layers",
+			historySizes: []int64{32, 0, 724531200},
+			emptyLayers:  []bool{false, true, false},
+			count:        2,
+			expected:     []int64{724531200, 32},
+		},
+		{
+			name:         "without history in the image",
+			historySizes: []int64{32, 724531200},
+			count:        2,
+			expected:     []int64{724531200, 32},
+		},
+		{
+			name:         "history not matching the layers",
+			historySizes: []int64{32, 724531200},
+			emptyLayers:  []bool{false, false},
+			count:        3,
+			expected:     []int64{-1, -1, -1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sizes := getLayerSizes(tc.historySizes, tc.emptyLayers, tc.count)
+			assert.Equal(t, tc.expected, sizes)
+		})
+	}
+}
+
+func TestGetContainerLabels(t *testing.T) {
+	testCases := []struct {
+		name     string
+		inspect  string
+		missing  bool
+		expected map[string]string
+		errMsg   string
+	}{
+		{
+			name: "labelled",
+			inspect: `[{"Id": "4c9f5ce5f4d4", "Config": {"Labels": {` +
+				`"com.github.containers.toolbox": "true", "owner": "rishi", "project": "gegl"}}}]`,
+			expected: map[string]string{
+				"com.github.containers.toolbox": "true",
+				"owner":                         "rishi",
+				"project":                       "gegl",
+			},
+		},
+		{
+			name:     "unlabelled",
+			inspect:  `[{"Id": "d5e0b4e3c6b1", "Config": {"Labels": null}}]`,
+			expected: map[string]string{},
+		},
+		{
+			name:    "missing",
+			missing: true,
+			errMsg:  "container fedora-toolbox-36 does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				switch call.Arg(3) {
+				case "container":
+					if tc.missing {
+						return 1
+					}
+				case "inspect":
+					fmt.Fprintln(call.Stdout, tc.inspect)
+				}
+
+				return 0
+			})
+
+			labels, err := GetContainerLabels("fedora-toolbox-36")
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, labels)
+		})
+	}
+}
+
+func TestLogs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  LogsOptions
+		expected string
+	}{
+		{
+			name:     "all",
+			expected: "--log-level error logs fedora-toolbox-36",
+		},
+		{
+			name:     "tail",
+			options:  LogsOptions{Tail: 10},
+			expected: "--log-level error logs --tail 10 fedora-toolbox-36",
+		},
+		{
+			name:     "follow",
+			options:  LogsOptions{Follow: true},
+			expected: "--log-level error logs --follow fedora-toolbox-36",
+		},
+		{
+			name:     "follow with tail",
+			options:  LogsOptions{Follow: true, Tail: 5},
+			expected: "--log-level error logs --follow --tail 5 fedora-toolbox-36",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				if call.Arg(3) == "logs" {
+					fmt.Fprintln(call.Stdout, "Welcome to fedora-toolbox:36")
+					fmt.Fprintln(call.Stderr, "bash: gegl: command not found")
+				}
+
+				return 0
+			})
+
+			var stderr, stdout bytes.Buffer
+			err := Logs(context.Background(), "fedora-toolbox-36", tc.options, &stdout, &stderr)
+			require.NoError(t, err)
+
+			assert.Equal(t, "Welcome to fedora-toolbox:36\n", stdout.String())
+			assert.Equal(t, "bash: gegl: command not found\n", stderr.String())
+
+			calls := r.Calls()
+			assert.Equal(t, tc.expected, calls[len(calls)-1])
+		})
+	}
+
+	t.Run("follow until cancelled", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			if call.Arg(3) != "logs" {
+				return 0
+			}
+
+			fmt.Fprintln(call.Stdout, "Welcome to fedora-toolbox:36")
+			return shelltest.Hang(call)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		var stdout bytes.Buffer
+		err := Logs(ctx, "fedora-toolbox-36", LogsOptions{Follow: true, Tail: 1}, &stdout, ioutil.Discard)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "Welcome to fedora-toolbox:36\n", stdout.String())
+	})
+
+	t.Run("negative tail", func(t *testing.T) {
+		err := Logs(context.Background(), "fedora-toolbox-36", LogsOptions{Tail: -1}, nil, nil)
+		assert.EqualError(t, err, "invalid number of lines -1")
+	})
+
+	t.Run("non-existent", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Exit(1))
+
+		err := Logs(context.Background(), "fedora-toolbox-36", LogsOptions{}, nil, nil)
+		assert.EqualError(t, err, "container fedora-toolbox-36 does not exist")
+	})
+}
+
+func TestGetImageLabels(t *testing.T) {
+	testCases := []struct {
+		name     string
+		inspect  string
+		missing  bool
+		expected map[string]string
+		errMsg   string
+	}{
+		{
+			name:    "labelled",
+			inspect: `[{"Id": "8b9affd1dbc2", "Labels": {"com.github.containers.toolbox": "true", "version": "36"}}]`,
+			expected: map[string]string{
+				"com.github.containers.toolbox": "true",
+				"version":                       "36",
+			},
+		},
+		{
+			name:     "unlabelled",
+			inspect:  `[{"Id": "d5e0b4e3c6b1", "Labels": null}]`,
+			expected: map[string]string{},
+		},
+		{
+			name:    "missing",
+			missing: true,
+			errMsg:  "image fedora-toolbox:36 does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				switch call.Arg(3) {
+				case "image":
+					if tc.missing {
+						return 1
+					}
+				case "inspect":
+					fmt.Fprintln(call.Stdout, tc.inspect)
+				}
+
+				return 0
+			})
+
+			labels, err := GetImageLabels("fedora-toolbox:36")
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, labels)
+		})
+	}
+}
+
+func TestCheckVersionFresh(t *testing.T) {
+	ResetVersionCache()
+	t.Cleanup(ResetVersionCache)
+
+	version := "1.9.0"
+
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		fmt.Fprintf(call.Stdout, `{"Client": {"Version": "%s"}}`+"\n", version)
+		return 0
+	})
+
+	assert.False(t, CheckVersion("2.1.0"))
+
+	version = "4.2.0"
+
+	assert.False(t, CheckVersion("2.1.0"))
+	assert.True(t, CheckVersionFresh("2.1.0"))
+	assert.True(t, CheckVersion("2.1.0"))
+
+	calls := r.Calls()
+	assert.Len(t, calls, 2)
+}
+
+func TestGetImagePlatforms(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "8b9affd1dbc2", "Os": "linux", "Architecture": "amd64"},
+  {"Id": "9c0b0ee2ecd3", "Os": "linux", "Architecture": "arm64", "Variant": "v8"},
+  {"Id": "f0e1d2c3b4a5"}
+]`))
+
+	images := []Image{
+		{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:36"}},
+		{ID: "8b9affd1dbc2", Names: []string{"fedora-toolbox:latest"}},
+		{ID: "9c0b0ee2ecd3", Names: []string{"fedora-toolbox-arm64:36"}},
+		{ID: "f0e1d2c3b4a5", Names: []string{"fedora-toolbox-multi-arch:36"}},
+	}
+
+	err := GetImagePlatforms(images)
+	require.NoError(t, err)
+
+	assert.Equal(t, "linux/amd64", images[0].Platform)
+	assert.Equal(t, "linux/amd64", images[1].Platform)
+	assert.Equal(t, "linux/arm64/v8", images[2].Platform)
+	assert.Empty(t, images[3].Platform, "manifest list")
+
+	calls := r.Calls()
+	expected := []string{
+		"--log-level error image inspect --format json 8b9affd1dbc2 9c0b0ee2ecd3 f0e1d2c3b4a5",
+	}
+
+	assert.Equal(t, expected, calls)
+}
+
+func TestFindMissingImages(t *testing.T) {
+	images := []Image{
+		{ID: "8b9affd1dbc2", Names: []string{"registry.fedoraproject.org/fedora-toolbox:36"}},
+		{ID: "9c0b0ee2ecd3"},
+	}
+
+	containers := []Container{
+		{ID: "4c9f5ce5f4d4", Image: "registry.fedoraproject.org/fedora-toolbox:36", ImageID: "8b9affd1dbc2"},
+		{ID: "d5e0b4e3c6b1", Image: "registry.fedoraproject.org/fedora-toolbox:35", ImageID: "a0c1b1ff3de4"},
+		{ID: "e6f1c5f4d7c2", Image: "registry.fedoraproject.org/fedora-toolbox:37", ImageID: "9c0b0ee2ecd3"},
+		{ID: "f7a2d6e5c8b3", Image: "registry.fedoraproject.org/fedora-toolbox:38"},
+	}
+
+	FindMissingImages(containers, images)
+
+	assert.False(t, containers[0].ImageMissing)
+	assert.True(t, containers[1].ImageMissing)
+	assert.False(t, containers[2].ImageMissing, "untagged image")
+	assert.False(t, containers[3].ImageMissing, "unknown image")
+}
+
+func TestGetImagePlatform(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "with variant",
+			output:   `[{"Id": "9c0b0ee2ecd3", "Os": "linux", "Architecture": "arm64", "Variant": "v8"}]`,
+			expected: "linux/arm64/v8",
+		},
+		{
+			name:     "without variant",
+			output:   `[{"Id": "8b9affd1dbc2", "Os": "linux", "Architecture": "amd64"}]`,
+			expected: "linux/amd64",
+		},
+		{
+			name:   "unknown",
+			output: `[{"Id": "f0e1d2c3b4a5"}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, SetRunner, shelltest.Output(tc.output))
+
+			platform, err := GetImagePlatform("fedora-toolbox:36")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, platform)
+		})
+	}
+}
+
+func TestPullPlatform(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Exit(0))
+
+	options := PullOptions{Platform: "linux/arm64"}
+	err := Pull("registry.fedoraproject.org/fedora-toolbox:36", options)
+	require.NoError(t, err)
+
+	calls := r.Calls()
+	expected := []string{
+		"--log-level error pull --platform linux/arm64 registry.fedoraproject.org/fedora-toolbox:36",
+	}
+
+	assert.Equal(t, expected, calls)
+}
+
+func TestGetImagesLargerThan(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "8b9affd1dbc2", "Names": ["registry.fedoraproject.org/fedora-toolbox:36"], "Size": 812345678},
+  {"Id": "9c0b0ee2ecd3", "Names": ["registry.fedoraproject.org/fedora-toolbox:37"], "Size": 1612345678},
+  {"Id": "d5e0b4e3c6b1", "Names": ["docker.io/library/alpine:latest"], "Size": 7340032},
+  {"Id": "e6f1c5f4d7c2", "Names": ["localhost/legacy:latest"], "Size": "1.2 GB"},
+  {"Id": "a7f2d6e5c8b3", "Names": ["localhost/unknown:latest"]}
+]`))
+
+	images, err := GetImagesLargerThan(500 * 1024 * 1024)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+
+	assert.Equal(t, []string{"9c0b0ee2ecd3", "e6f1c5f4d7c2", "8b9affd1dbc2"}, ids)
+	assert.Equal(t, int64(1200000000), images[1].SizeBytes)
+
+	images, err = GetImagesLargerThan(-1)
+	require.NoError(t, err)
+	assert.Len(t, images, 4)
+}
+
+func TestErrorsIncludeStderr(t *testing.T) {
+	const stderr = "Error: something went wrong"
+
+	testCases := []struct {
+		name string
+		run  func() error
+	}{
+		{
+			name: "ContainerExists",
+			run: func() error {
+				_, err := ContainerExists("fedora-toolbox-36")
+				return err
+			},
+		},
+		{
+			name: "GetContainers",
+			run: func() error {
+				_, err := GetContainers()
+				return err
+			},
+		},
+		{
+			name: "GetImages",
+			run: func() error {
+				_, err := GetImages()
+				return err
+			},
+		},
+		{
+			name: "ImageExists",
+			run: func() error {
+				_, err := ImageExists("fedora-toolbox:36")
+				return err
+			},
+		},
+		{
+			name: "Pull",
+			run: func() error {
+				return Pull("registry.fedoraproject.org/fedora-toolbox:36", PullOptions{})
+			},
+		},
+		{
+			name: "RemoveContainer",
+			run: func() error {
+				return RemoveContainer("fedora-toolbox-36", true)
+			},
+		},
+		{
+			name: "RemoveImage",
+			run: func() error {
+				return RemoveImage("fedora-toolbox:36", true)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shelltest.SetUp(t, SetRunner,
+				shelltest.Fail("Trying to pull registry.fedoraproject.org/fedora-toolbox:36...\n"+stderr+"\n", 125))
+
+			err := tc.run()
+			require.Error(t, err)
+			assert.True(t, strings.HasSuffix(err.Error(), ": something went wrong"), err.Error())
+			assert.NotContains(t, err.Error(), "Trying to pull")
+		})
+	}
+}
+
+func TestGetContainersRetry(t *testing.T) {
+	delay := listRetryDelay
+	listRetryDelay = 0
+	t.Cleanup(func() {
+		listRetryDelay = delay
+	})
+
+	testCases := []struct {
+		name   string
+		stderr string
+		fails  int
+		calls  int
+		errMsg string
+	}{
+		{
+			name:   "transient error once",
+			stderr: "Error: no such container 4c9f5ce5f4d4",
+			fails:  1,
+			calls:  2,
+		},
+		{
+			name:   "transient error every time",
+			stderr: "Error: database is locked",
+			fails:  3,
+			calls:  3,
+			errMsg: "failed to invoke podman(1): database is locked",
+		},
+		{
+			name:   "permanent error",
+			stderr: "Error: unknown flag: --format",
+			fails:  1,
+			calls:  1,
+			errMsg: "failed to invoke podman(1): unknown flag: --format",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				calls++
+				if calls <= tc.fails {
+					fmt.Fprintln(call.Stderr, tc.stderr)
+					return 125
+				}
+
+				fmt.Fprintln(call.Stdout, `[{"Names": ["fedora-toolbox-36"], "State": "running"}]`)
+				return 0
+			})
+
+			containers, err := GetContainers("--all")
+			assert.Len(t, r.Calls(), tc.calls)
+
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, containers, 1)
+			assert.Equal(t, "fedora-toolbox-36", containers[0].Names[0])
+		})
+	}
+}
+
+func TestErrorWithStderr(t *testing.T) {
+	err := errors.New("failed to invoke podman(1)")
+
+	var stderr bytes.Buffer
+	assert.Equal(t, err, errorWithStderr(err, &stderr))
+
+	stderr.WriteString("no such image\n")
+	wrapped := errorWithStderr(err, &stderr)
+	assert.EqualError(t, wrapped, "failed to invoke podman(1): no such image")
+	assert.ErrorIs(t, wrapped, err)
+
+	stderr.Reset()
+	stderr.WriteString("Error: open /home/rishi/.local/share/containers/storage/overlay-images/images.lock: " +
+		"Permission denied\n")
+	wrapped = errorWithStderr(err, &stderr)
+	assert.ErrorIs(t, wrapped, ErrPermissionDenied)
+	assert.EqualError(t, wrapped, "insufficient permissions to use Podman: "+
+		"open /home/rishi/.local/share/containers/storage/overlay-images/images.lock: Permission denied")
+}
+
+func TestPingBackend(t *testing.T) {
+	ResetVersionCache()
+	t.Cleanup(ResetVersionCache)
+
+	t.Run("reachable", func(t *testing.T) {
+		t.Cleanup(ResetVersionCache)
+
+		r := shelltest.SetUp(t, SetRunner, shelltest.Output(`{"Client": {"Version": "4.2.0"}}`))
+
+		err := PingBackend(context.Background())
+		assert.NoError(t, err)
+
+		err = EnsureSupportedBackend()
+		assert.NoError(t, err)
+
+		err = PingBackend(context.Background())
+		assert.NoError(t, err)
+
+		assert.Len(t, r.Calls(), 1)
+	})
+
+	t.Run("not working", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Fail("Error: cannot re-exec process\n", 125))
+
+		err := PingBackend(context.Background())
+		assert.EqualError(t, err, "podman(1) is not working: failed to invoke podman(1): cannot re-exec process")
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Fail("Error: mkdir /run/user/1000/libpod: permission denied\n", 125))
+
+		err := PingBackend(context.Background())
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		assert.EqualError(t, err,
+			"insufficient permissions to use Podman: mkdir /run/user/1000/libpod: permission denied\n"+
+				"Run Toolbox as a regular user without sudo, and check that rootless Podman is set up "+
+				"for the user and that none of its files were created with sudo.")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, nil)
+
+		err := PingBackend(context.Background())
+		assert.EqualError(t, err, "podman(1) not found")
+	})
+}
+
+func TestCopyToContainer(t *testing.T) {
+	srcPath := filepath.Join(t.TempDir(), "bashrc")
+	err := ioutil.WriteFile(srcPath, []byte("export EDITOR=vi\n"), 0644)
+	require.NoError(t, err)
+
+	t.Run("running container", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+			if call.Arg(3) == "inspect" {
+				fmt.Fprintln(call.Stdout, `[{"State": {"Status": "running"}}]`)
+			}
+
+			return 0
+		})
+
+		err := CopyToContainer("fedora-toolbox-36", srcPath, "/etc/skel/.bashrc")
+		assert.NoError(t, err)
+
+		calls := r.Calls()
+		assert.Contains(t, calls, "--log-level error cp "+srcPath+" fedora-toolbox-36:/etc/skel/.bashrc")
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"State": {"Status": "running"}}]`))
+
+		missingPath := filepath.Join(t.TempDir(), "missing")
+		err := CopyToContainer("fedora-toolbox-36", missingPath, "/etc/skel/.bashrc")
+		assert.EqualError(t, err, "source "+missingPath+" does not exist")
+
+		calls := r.Calls()
+		assert.Empty(t, calls)
+	})
+
+	t.Run("exited container", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"State": {"Status": "exited"}}]`))
+
+		err := CopyToContainer("fedora-toolbox-36", srcPath, "/etc/skel/.bashrc")
+		assert.EqualError(t, err, "container fedora-toolbox-36 is not running")
+	})
+}
+
+func TestImageUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name:     "string",
+			data:     `{"Id": "8b9affd1dbc2", "Names": "registry.fedoraproject.org/fedora-toolbox:36"}`,
+			expected: []string{"registry.fedoraproject.org/fedora-toolbox:36"},
+		},
+		{
+			name: "array",
+			data: `{"Id": "8b9affd1dbc2", "Names": [
+  "registry.fedoraproject.org/fedora-toolbox:36",
+  "localhost/fedora-toolbox:36"
+]}`,
+			expected: []string{
+				"registry.fedoraproject.org/fedora-toolbox:36",
+				"localhost/fedora-toolbox:36",
+			},
+		},
+		{
+			name: "none",
+			data: `{"Id": "8b9affd1dbc2", "Names": null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var image Image
+			err := json.Unmarshal([]byte(tc.data), &image)
+			assert.NoError(t, err)
+			assert.Equal(t, "8b9affd1dbc2", image.ID)
+			assert.Equal(t, tc.expected, image.Names)
+		})
+	}
+}
+
+func TestParseDiskUsage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected DiskUsage
+		errMsg   string
+	}{
+		{
+			name: "raw sizes",
+			data: `[
+  {"Type": "Images", "Total": 3, "Active": 1, "RawSize": 1612345678, "Size": "1.612GB"},
+  {"Type": "Containers", "Total": 2, "Active": 1, "RawSize": 40960, "Size": "40.96kB"},
+  {"Type": "Local Volumes", "Total": 0, "Active": 0, "RawSize": 0, "Size": "0B"}
+]`,
+			expected: DiskUsage{Images: 1612345678, Containers: 40960},
+		},
+		{
+			name: "human-readable sizes",
+			data: `[
+  {"Type": "Images", "Size": "1.2GB"},
+  {"Type": "Containers", "Size": "40.96kB"},
+  {"Type": "Local Volumes", "Size": "1MB"}
+]`,
+			expected: DiskUsage{Images: 1200000000, Containers: 40960, Volumes: 1000000},
+		},
+		{
+			name: "empty",
+			data: `[]`,
+		},
+		{
+			name:   "invalid size",
+			data:   `[{"Type": "Images", "Size": "a lot"}]`,
+			errMsg: "invalid size a lot",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diskUsage, err := parseDiskUsage([]byte(tc.data))
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, diskUsage)
+		})
+	}
+}
+
+func TestGetDiskUsage(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"Type": "Images", "RawSize": 812345678}]`))
+
+	diskUsage, err := GetDiskUsage()
+	assert.NoError(t, err)
+	assert.Equal(t, DiskUsage{Images: 812345678}, diskUsage)
+
+	calls := r.Calls()
+	assert.Equal(t, []string{"--log-level error system df --format json"}, calls)
+}
+
+const fakeSystemDfVerbose = `Images space usage:
+
+REPOSITORY                                 TAG         IMAGE ID      CREATED       SIZE        SHARED SIZE  UNIQUE SIZE  CONTAINERS
+registry.fedoraproject.org/fedora-toolbox  36          8b9affd1dbc2  3 months ago  1.61GB      1.2GB        410MB        2
+<none>                                     <none>      e6f1c5f4d7c2  2 weeks ago   5.24MB      0B           5.24MB       0
+
+Containers space usage:
+
+CONTAINER ID  IMAGE         COMMAND     LOCAL VOLUMES  SIZE        CREATED       STATUS      NAMES
+4c9f5ce5f4d4  8b9affd1dbc2  toolbox     0              41kB        2 weeks ago   Up 2 hours  fedora-toolbox-36
+
+Local Volumes space usage:
+
+VOLUME NAME  LINKS       SIZE
+`
+
+func TestParseImagesDiskUsage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []ImageDiskUsage
+		errMsg   string
+	}{
+		{
+			name:   "images",
+			output: fakeSystemDfVerbose,
+			expected: []ImageDiskUsage{
+				{ID: "8b9affd1dbc2", SizeBytes: 1610000000, SharedBytes: 1200000000, UniqueBytes: 410000000, Containers: 2},
+				{ID: "e6f1c5f4d7c2", SizeBytes: 5240000, UniqueBytes: 5240000},
+			},
+		},
+		{
+			name: "no images",
+			output: "Images space usage:\n\n" +
+				"REPOSITORY  TAG  IMAGE ID  CREATED  SIZE  SHARED SIZE  UNIQUE SIZE  CONTAINERS\n\n",
+		},
+		{
+			name:   "no table",
+			output: "Error: something went wrong\n",
+			errMsg: "missing table of images",
+		},
+		{
+			name: "missing column",
+			output: "REPOSITORY  TAG  IMAGE ID  CREATED  SIZE  CONTAINERS\n" +
+				"fedora      36   8b9affd1dbc2  3 months ago  1.61GB  2\n",
+			errMsg: "missing column SHARED SIZE",
+		},
+		{
+			name: "invalid size",
+			output: "REPOSITORY  TAG  IMAGE ID  CREATED  SIZE  SHARED SIZE  UNIQUE SIZE  CONTAINERS\n" +
+				"fedora  36  8b9affd1dbc2  3 months ago  lots  0B  0B  2\n",
+			errMsg: "invalid size lots",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			images, err := parseImagesDiskUsage(tc.output)
+
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, images)
+		})
+	}
+}
+
+func TestGetImagesDiskUsage(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(fakeSystemDfVerbose))
+
+	images, err := GetImagesDiskUsage()
+	assert.NoError(t, err)
+	assert.Len(t, images, 2)
+
+	calls := r.Calls()
+	assert.Equal(t, []string{"--log-level error system df --verbose"}, calls)
+}
+
+func TestPullIfMissing(t *testing.T) {
+	testCases := []struct {
+		name     string
+		image    string
+		present  string
+		pulled   bool
+		lookedUp []string
+	}{
+		{
+			name:     "present",
+			image:    "registry.fedoraproject.org/fedora-toolbox:36",
+			present:  "registry.fedoraproject.org/fedora-toolbox:36",
+			lookedUp: []string{"registry.fedoraproject.org/fedora-toolbox:36"},
+		},
+		{
+			name:    "present under the normalized name",
+			image:   "alpine",
+			present: "docker.io/library/alpine:latest",
+			lookedUp: []string{
+				"alpine",
+				"docker.io/library/alpine:latest",
+			},
+		},
+		{
+			name:     "missing",
+			image:    "registry.fedoraproject.org/fedora-toolbox:37",
+			present:  "registry.fedoraproject.org/fedora-toolbox:36",
+			pulled:   true,
+			lookedUp: []string{"registry.fedoraproject.org/fedora-toolbox:37"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				if call.Arg(3) == "image" && call.Arg(5) != tc.present {
+					return 1
+				}
+
+				return 0
+			})
+
+			pulled, err := PullIfMissing(tc.image, PullOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.pulled, pulled)
+
+			var lookedUp []string
+			var pullCall bool
+
+			for _, call := range r.Calls() {
+				if strings.HasPrefix(call, "--log-level error image exists ") {
+					lookedUp = append(lookedUp, strings.TrimPrefix(call, "--log-level error image exists "))
+				}
+
+				if call == "--log-level error pull "+tc.image {
+					pullCall = true
+				}
+			}
+
+			assert.Equal(t, tc.lookedUp, lookedUp)
+			assert.Equal(t, tc.pulled, pullCall)
+		})
+	}
+}
+
+func TestResolveContainerID(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01", "Names": ["fedora-toolbox-36"]},
+  {"Id": "4c9f11e2a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b2c3d4", "Names": ["gegl"]},
+  {"Id": "d5e0b4e3c6b1f2b3a8c7d9e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6", "Names": ["fedora-toolbox-37"]}
+]`))
+
+	testCases := []struct {
+		name     string
+		target   string
+		expected string
+		errMsg   string
+	}{
+		{
+			name:     "name",
+			target:   "fedora-toolbox-37",
+			expected: "d5e0b4e3c6b1f2b3a8c7d9e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6",
+		},
+		{
+			name:     "short ID",
+			target:   "4c9f5ce5f4d4",
+			expected: "4c9f5ce5f4d4f5e4b1dd4f1bd3ca7f29e9df5e7bfd4d8a0e1ad35bfd4c8a6e01",
+		},
+		{
+			name:   "miss",
+			target: "fedora-toolbox-38",
+			errMsg: "container fedora-toolbox-38 not found",
+		},
+		{
+			name:   "ambiguous",
+			target: "4c9f",
+			errMsg: "container 4c9f is ambiguous: it matches 2 containers",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := ResolveContainerID(tc.target)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, id)
+		})
+	}
+}
+
+func TestStop(t *testing.T) {
+	handler := func(call shelltest.Call) int {
+		if call.Arg(6) == "stuck" {
+			fmt.Fprintln(call.Stderr, "Error: given PID did not die within timeout")
+			return 125
+		}
+
+		return 0
+	}
+
+	t.Run("success", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, handler)
+
+		err := Stop("fedora-toolbox-36", 30)
+		require.NoError(t, err)
+
+		calls := r.Calls()
+		assert.Contains(t, calls, "--log-level error stop --time 30 fedora-toolbox-36")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, handler)
+
+		err := Stop("stuck", 0)
+		assert.EqualError(t, err, "failed to invoke podman(1): given PID did not die within timeout")
+	})
+}
+
+func TestStopContext(t *testing.T) {
+	shelltest.SetUp(t, SetRunner, shelltest.Hang)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := StopContext(ctx, "fedora-toolbox-36", 10)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestTop(t *testing.T) {
+	handler := func(call shelltest.Call) int {
+		switch call.Arg(3) {
+		case "inspect":
+			if call.Arg(8) == "fedora-toolbox-36" {
+				fmt.Fprintln(call.Stdout, `[{"State": {"Status": "running"}}]`)
+			} else {
+				fmt.Fprintln(call.Stdout, `[{"State": {"Status": "exited"}}]`)
+			}
+		case "top":
+			if call.Arg(5) == "bogus" {
+				fmt.Fprintln(call.Stderr, "Error: unknown descriptor: bogus")
+				return 125
+			}
+
+			fmt.Fprintln(call.Stdout, "PID   COMMAND")
+			fmt.Fprintln(call.Stdout, "1     toolbox")
+		}
+
+		return 0
+	}
+
+	t.Run("running container", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, handler)
+
+		var stdout strings.Builder
+		err := Top("fedora-toolbox-36", []string{"pid", "comm"}, &stdout)
+		require.NoError(t, err)
+		assert.Equal(t, "PID   COMMAND\n1     toolbox\n", stdout.String())
+
+		calls := r.Calls()
+		assert.Contains(t, calls, "--log-level error top fedora-toolbox-36 pid comm")
+	})
+
+	t.Run("exited container", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, handler)
+
+		err := Top("fedora-toolbox-37", nil, ioutil.Discard)
+		assert.EqualError(t, err, "container fedora-toolbox-37 is not running")
+	})
+
+	t.Run("invalid descriptor", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, handler)
+
+		err := Top("fedora-toolbox-36", []string{"bogus"}, ioutil.Discard)
+		assert.EqualError(t, err, "failed to invoke podman(1): unknown descriptor: bogus")
+	})
+}
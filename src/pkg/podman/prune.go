@@ -0,0 +1,298 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/containers/toolbox/pkg/utils"
+)
+
+// PruneReport lists what a prune removed, or would remove in a dry run.
+type PruneReport struct {
+	// Removed holds the names of the removed containers or images.
+	Removed []string
+
+	// IDs holds the ID of each removed container or image, keyed by name.
+	IDs map[string]string
+
+	// Sizes holds the size in bytes of each removed container or image,
+	// keyed by name, or -1 if it's unknown.
+	Sizes map[string]int64
+
+	// ReclaimedBytes is the space freed by the removal. Objects of
+	// unknown size don't count towards it.
+	ReclaimedBytes int64
+}
+
+// PruneContainers removes the toolbox containers that aren't running or
+// paused. Those are the ones that have exited, were created but never started,
+// or are in the "unknown" state, which is usually because they are broken.
+// Containers that are being stopped or removed, or whose state isn't
+// recognised by NormalizeTaskState, are left alone. If until isn't the zero time, only the containers that
+// stopped before it are removed, as explained for getContainerStoppedAt. If
+// dryRun is true, nothing is removed, but the report lists the containers that
+// would be.
+//
+// Like RemoveAllContainers, it doesn't stop at the first failure, and the
+// errors are keyed by container name. The last return value is only set if
+// the containers couldn't be listed. Once ctx is done, no more containers are
+// listed or removed.
+func PruneContainers(ctx context.Context, dryRun bool, until time.Time) (PruneReport, map[string]error, error) {
+	containers, err := GetContainersContext(ctx, "--all", "--size")
+	if err != nil {
+		return PruneReport{}, nil, errors.New("failed to get containers")
+	}
+
+	report := newPruneReport()
+
+	for _, container := range containers {
+		if !container.IsToolbox() {
+			continue
+		}
+
+		if container.Status != "created" && container.Status != "exited" && container.Status != "unknown" {
+			continue
+		}
+
+		if !until.IsZero() {
+			stoppedAt := getContainerStoppedAt(container)
+			if stoppedAt.IsZero() || !stoppedAt.Before(until) {
+				continue
+			}
+		}
+
+		name := container.ID
+		if len(container.Names) != 0 {
+			name = container.Names[0]
+		}
+
+		report.add(name, container.ID, container.SizeBytes)
+	}
+
+	if dryRun {
+		return report, make(map[string]error), nil
+	}
+
+	report, errs := RemovePrunedContainers(ctx, report)
+	return report, errs, nil
+}
+
+// RemovePrunedContainers removes the containers listed by a dry run of
+// PruneContainers, and reports those that were removed. Like
+// RemovePrunedImages, it doesn't look for containers again. Containers that
+// were started in the meantime are kept, because Podman refuses to remove
+// them. It stops early, without an error, once ctx is done.
+func RemovePrunedContainers(ctx context.Context, report PruneReport) (PruneReport, map[string]error) {
+	removed := newPruneReport()
+	errs := make(map[string]error)
+
+	for _, name := range report.Removed {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := RemoveContainer(name, false); err != nil {
+			errs[name] = err
+			continue
+		}
+
+		removed.add(name, report.IDs[name], report.Sizes[name])
+	}
+
+	return removed, errs
+}
+
+// getContainerStoppedAt returns when a container that isn't running stopped,
+// which is when it was created if it was never started. Broken containers
+// that never reported exiting are also treated as stopped since they were
+// created. It's the zero time if unknown, like with Podman V1.
+func getContainerStoppedAt(container Container) time.Time {
+	if container.Status == "created" {
+		return container.CreatedAt
+	}
+
+	if container.Status == "unknown" && container.ExitedAt.IsZero() {
+		return container.CreatedAt
+	}
+
+	return container.ExitedAt
+}
+
+// PruneImages removes the toolbox images that aren't used by any container.
+// If dryRun is true, nothing is removed, but the report lists the images that
+// would be.
+//
+// It reports its progress and errors like PruneContainers. Images are named
+// by their first name, or their short ID if they have none. An image with
+// several names can be listed once for each of them, but it's only reported
+// and removed once.
+func PruneImages(ctx context.Context, dryRun bool) (PruneReport, map[string]error, error) {
+	images, err := GetImagesContext(ctx)
+	if err != nil {
+		return PruneReport{}, nil, errors.New("failed to get images")
+	}
+
+	containers, err := GetContainersContext(ctx, "--all")
+	if err != nil {
+		return PruneReport{}, nil, errors.New("failed to get containers")
+	}
+
+	report := newPruneReport()
+	processed := make(map[string]struct{})
+
+	for _, image := range images {
+		if _, ok := processed[image.ID]; ok {
+			continue
+		}
+
+		processed[image.ID] = struct{}{}
+
+		if !image.IsToolbox() || isImageUsed(image, containers) {
+			continue
+		}
+
+		name := utils.ShortID(image.ID)
+		if len(image.Names) != 0 {
+			name = image.Names[0]
+		}
+
+		report.add(name, image.ID, image.SizeBytes)
+	}
+
+	if dryRun {
+		return report, make(map[string]error), nil
+	}
+
+	report, errs := RemovePrunedImages(ctx, report)
+	return report, errs, nil
+}
+
+// RemovePrunedImages removes the images listed by a dry run of PruneImages,
+// and reports those that were removed. Unlike PruneImages, it doesn't look for
+// unused images again, so that only the ones that the user was shown are
+// removed. Images that a container started using in the meantime are kept,
+// because Podman refuses to remove them. Like RemovePrunedContainers, it
+// stops early once ctx is done.
+func RemovePrunedImages(ctx context.Context, report PruneReport) (PruneReport, map[string]error) {
+	removed := newPruneReport()
+	errs := make(map[string]error)
+
+	for _, name := range report.Removed {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := removeImage(report.IDs[name], false); err != nil {
+			errs[name] = err
+			continue
+		}
+
+		removed.add(name, report.IDs[name], report.Sizes[name])
+	}
+
+	return removed, errs
+}
+
+// isImageUsed checks if any of the containers was created from the image.
+// Podman V1 doesn't report the IDs of the images of containers, so their names
+// are compared too.
+func isImageUsed(image Image, containers []Container) bool {
+	for _, container := range containers {
+		if container.ImageID != "" && container.ImageID == image.ID {
+			return true
+		}
+
+		for _, name := range image.Names {
+			if container.Image == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func newPruneReport() PruneReport {
+	return PruneReport{IDs: make(map[string]string), Sizes: make(map[string]int64)}
+}
+
+// add adds a removed container or image to the report.
+func (report *PruneReport) add(name, id string, size int64) {
+	report.Removed = append(report.Removed, name)
+	report.IDs[name] = id
+	report.Sizes[name] = size
+
+	if size > 0 {
+		report.ReclaimedBytes += size
+	}
+}
+
+// GarbageCollect removes dangling images, which are the untagged layers left
+// behind when an image is pulled again or rebuilt under the same name, and
+// returns the space that was reclaimed. Dangling images used by containers
+// are kept. Having nothing to remove isn't an error.
+//
+// Unlike PruneImages, this isn't limited to toolbox images, because dangling
+// images have lost the names that would tell who they belong to.
+func GarbageCollect() (int64, error) {
+	images, err := GetImages("--filter", "dangling=true")
+	if err != nil {
+		return 0, errors.New("failed to get images")
+	}
+
+	if len(images) == 0 {
+		return 0, nil
+	}
+
+	var stderr, stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "image", "prune", "--force"}
+
+	if err := run(context.Background(), nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		return 0, errorWithStderr(err, &stderr)
+	}
+
+	return getReclaimedBytes(images, stdout.String()), nil
+}
+
+// getReclaimedBytes adds up the sizes of the images whose IDs are listed in
+// the output of 'podman image prune', one per line. The IDs can be shortened.
+func getReclaimedBytes(images []Image, output string) int64 {
+	var reclaimed int64
+
+	for _, line := range strings.Split(output, "\n") {
+		id := strings.TrimSpace(line)
+		if id == "" {
+			continue
+		}
+
+		for _, image := range images {
+			if strings.HasPrefix(image.ID, id) && image.SizeBytes > 0 {
+				reclaimed += image.SizeBytes
+				break
+			}
+		}
+	}
+
+	return reclaimed
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakePruneImagesJSON = `[
+  {"Id": "8b9affd1dbc2", "Names": ["fedora-toolbox:36"], "Size": 812345678,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "9c0b0ee2ecd3", "Names": ["fedora-toolbox:37"], "Size": 912345678,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "d5e0b4e3c6b1", "Names": ["alpine:latest"], "Size": 7340032}
+]`
+
+const fakePruneContainersJSON = `[
+  {"Id": "4c9f5ce5f4d4", "Names": ["fedora-toolbox-36"], "State": "running", "ImageID": "8b9affd1dbc2",
+   "Labels": {"com.github.containers.toolbox": "true"}, "Size": {"rwSize": 4096}},
+  {"Id": "d5e0b4e3c6b1", "Names": ["fedora-toolbox-37"], "State": "exited", "ImageID": "0e3c2b1a9f8d",
+   "Labels": {"com.github.containers.toolbox": "true"}, "Size": {"rwSize": 40960}},
+  {"Id": "e6f1c5f4d7c2", "Names": ["never-started"], "State": "created", "ImageID": "0e3c2b1a9f8d",
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "b8e3f7a6d9c4", "Names": ["broken"], "State": "unknown",
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "c9f4a8b7e0d5", "Names": ["being-stopped"], "State": "stopping",
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "f7a2d6e5c8b3", "Names": ["being-removed"], "State": "removing",
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "0a1b2c3d4e5f", "Names": ["from-the-future"], "State": "hibernating",
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "a7f2d6e5c8b3", "Names": ["web-server"], "State": "exited", "Image": "alpine:latest"}
+]`
+
+func fakePrunePodman(call shelltest.Call) int {
+	switch call.Arg(3) {
+	case "images":
+		fmt.Fprintln(call.Stdout, fakePruneImagesJSON)
+	case "ps":
+		fmt.Fprintln(call.Stdout, fakePruneContainersJSON)
+	}
+
+	return 0
+}
+
+func countRemovals(calls []string) int {
+	var count int
+
+	for _, call := range calls {
+		if strings.HasPrefix(call, "--log-level error rm ") || strings.HasPrefix(call, "--log-level error rmi ") {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestPruneContainers(t *testing.T) {
+	expected := PruneReport{
+		Removed: []string{"fedora-toolbox-37", "never-started", "broken"},
+		IDs: map[string]string{
+			"fedora-toolbox-37": "d5e0b4e3c6b1",
+			"never-started":     "e6f1c5f4d7c2",
+			"broken":            "b8e3f7a6d9c4",
+		},
+		Sizes:          map[string]int64{"fedora-toolbox-37": 40960, "never-started": -1, "broken": -1},
+		ReclaimedBytes: 40960,
+	}
+
+	r := shelltest.SetUp(t, SetRunner, fakePrunePodman)
+
+	report, errs, err := PruneContainers(context.Background(), true, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, expected, report)
+	assert.Equal(t, 0, countRemovals(r.Calls()))
+
+	r = shelltest.SetUp(t, SetRunner, fakePrunePodman)
+
+	report, errs, err = PruneContainers(context.Background(), false, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, expected, report)
+
+	calls := r.Calls()
+	assert.Contains(t, calls, "--log-level error rm fedora-toolbox-37")
+	assert.Contains(t, calls, "--log-level error rm never-started")
+	assert.Contains(t, calls, "--log-level error rm broken")
+	assert.Equal(t, 3, countRemovals(calls))
+}
+
+func TestPruneContainersUntil(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[
+  {"Id": "4c9f5ce5f4d4", "Names": ["old"], "State": "exited", "Created": 1500000000, "ExitedAt": 1600000000,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "d5e0b4e3c6b1", "Names": ["recent"], "State": "exited", "Created": 1500000000, "ExitedAt": 1700000000,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "e6f1c5f4d7c2", "Names": ["old-never-started"], "State": "created", "Created": 1500000000,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "f7a2d6e5c8b3", "Names": ["unknown"], "State": "exited", "Created": 1500000000,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "b8e3f7a6d9c4", "Names": ["old-broken"], "State": "unknown", "Created": 1500000000,
+   "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "c9f4a8b7e0d5", "Names": ["recent-broken"], "State": "unknown", "Created": 1700000000,
+   "Labels": {"com.github.containers.toolbox": "true"}}
+]`))
+
+	report, errs, err := PruneContainers(context.Background(), true, time.Unix(1650000000, 0))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"old", "old-never-started", "old-broken"}, report.Removed)
+	assert.Equal(t, 0, countRemovals(r.Calls()))
+}
+
+func TestRemovePrunedContainers(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		if call.Arg(4) != "old" {
+			return 2
+		}
+
+		return 0
+	})
+
+	report := newPruneReport()
+	report.add("old", "4c9f5ce5f4d4", 40960)
+	report.add("started-since", "d5e0b4e3c6b1", 4096)
+
+	removed, errs := RemovePrunedContainers(context.Background(), report)
+
+	expected := PruneReport{
+		Removed:        []string{"old"},
+		IDs:            map[string]string{"old": "4c9f5ce5f4d4"},
+		Sizes:          map[string]int64{"old": 40960},
+		ReclaimedBytes: 40960,
+	}
+
+	assert.Equal(t, expected, removed)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["started-since"], ErrContainerRunning)
+
+	calls := r.Calls()
+	assert.Equal(t, []string{
+		"--log-level error rm old",
+		"--log-level error rm started-since",
+	}, calls)
+}
+
+func TestPruneImages(t *testing.T) {
+	expected := PruneReport{
+		Removed:        []string{"fedora-toolbox:37"},
+		IDs:            map[string]string{"fedora-toolbox:37": "9c0b0ee2ecd3"},
+		Sizes:          map[string]int64{"fedora-toolbox:37": 912345678},
+		ReclaimedBytes: 912345678,
+	}
+
+	r := shelltest.SetUp(t, SetRunner, fakePrunePodman)
+
+	report, errs, err := PruneImages(context.Background(), true)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, expected, report)
+	assert.Equal(t, 0, countRemovals(r.Calls()))
+
+	r = shelltest.SetUp(t, SetRunner, fakePrunePodman)
+
+	report, errs, err = PruneImages(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, expected, report)
+
+	calls := r.Calls()
+	assert.Contains(t, calls, "--log-level error rmi 9c0b0ee2ecd3")
+	assert.Equal(t, 1, countRemovals(calls))
+
+	var listCalls int
+	for _, call := range calls {
+		if strings.HasPrefix(call, "--log-level error ps ") {
+			listCalls++
+		}
+	}
+
+	assert.Equal(t, 1, listCalls)
+}
+
+func TestPruneImagesWithSeveralNames(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		switch call.Arg(3) {
+		case "images":
+			fmt.Fprintln(call.Stdout, `[
+  {"Id": "9c0b0ee2ecd3", "Names": ["fedora-toolbox:37", "registry.fedoraproject.org/fedora-toolbox:37"],
+   "Size": 912345678, "Labels": {"com.github.containers.toolbox": "true"}},
+  {"Id": "9c0b0ee2ecd3", "Names": ["registry.fedoraproject.org/fedora-toolbox:37"],
+   "Size": 912345678, "Labels": {"com.github.containers.toolbox": "true"}}
+]`)
+		case "ps":
+			fmt.Fprintln(call.Stdout, "[]")
+		}
+
+		return 0
+	})
+
+	report, errs, err := PruneImages(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	expected := PruneReport{
+		Removed:        []string{"fedora-toolbox:37"},
+		IDs:            map[string]string{"fedora-toolbox:37": "9c0b0ee2ecd3"},
+		Sizes:          map[string]int64{"fedora-toolbox:37": 912345678},
+		ReclaimedBytes: 912345678,
+	}
+
+	assert.Equal(t, expected, report)
+
+	calls := r.Calls()
+	assert.Contains(t, calls, "--log-level error rmi 9c0b0ee2ecd3")
+	assert.Equal(t, 1, countRemovals(calls))
+}
+
+func TestRemovePrunedImages(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+		if call.Arg(4) != "9c0b0ee2ecd3" {
+			return 2
+		}
+
+		return 0
+	})
+
+	report := newPruneReport()
+	report.add("fedora-toolbox:37", "9c0b0ee2ecd3", 912345678)
+	report.add("fedora-toolbox:38", "a0c1b1ff3de4", 1012345678)
+
+	removed, errs := RemovePrunedImages(context.Background(), report)
+
+	expected := PruneReport{
+		Removed:        []string{"fedora-toolbox:37"},
+		IDs:            map[string]string{"fedora-toolbox:37": "9c0b0ee2ecd3"},
+		Sizes:          map[string]int64{"fedora-toolbox:37": 912345678},
+		ReclaimedBytes: 912345678,
+	}
+
+	assert.Equal(t, expected, removed)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["fedora-toolbox:38"], ErrImageHasChildren)
+
+	calls := r.Calls()
+	assert.Equal(t, []string{
+		"--log-level error rmi 9c0b0ee2ecd3",
+		"--log-level error rmi a0c1b1ff3de4",
+	}, calls)
+}
+
+func TestRemovePrunedContainersCancelled(t *testing.T) {
+	r := shelltest.SetUp(t, SetRunner, shelltest.Exit(0))
+
+	report := newPruneReport()
+	report.add("old", "4c9f5ce5f4d4", 40960)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	removed, errs := RemovePrunedContainers(ctx, report)
+	assert.Empty(t, removed.Removed)
+	assert.Empty(t, errs)
+	assert.Equal(t, 0, countRemovals(r.Calls()))
+}
+
+func TestGarbageCollect(t *testing.T) {
+	testCases := []struct {
+		name      string
+		images    string
+		pruned    string
+		reclaimed int64
+		prune     bool
+	}{
+		{
+			name: "dangling images",
+			images: `[
+  {"Id": "3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a", "Names": null, "Size": 104857600},
+  {"Id": "7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d", "Names": null, "Size": 5242880},
+  {"Id": "b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b1a0", "Names": null, "Size": 1048576}
+]`,
+			pruned: "3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a\n" +
+				"7e6d5c4b3a2f\n",
+			reclaimed: 104857600 + 5242880,
+			prune:     true,
+		},
+		{
+			name:   "nothing to reclaim",
+			images: `[]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shelltest.SetUp(t, SetRunner, func(call shelltest.Call) int {
+				switch call.Arg(3) {
+				case "images":
+					fmt.Fprintln(call.Stdout, tc.images)
+				case "image":
+					fmt.Fprint(call.Stdout, tc.pruned)
+				}
+
+				return 0
+			})
+
+			reclaimed, err := GarbageCollect()
+			require.NoError(t, err)
+			assert.Equal(t, tc.reclaimed, reclaimed)
+
+			calls := r.Calls()
+			assert.Equal(t, "--log-level error images --format json --filter dangling=true", calls[0])
+
+			if tc.prune {
+				assert.Equal(t, []string{"--log-level error image prune --force"}, calls[1:])
+			} else {
+				assert.Len(t, calls, 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+)
+
+// Stats is a snapshot of the resource usage of a running container.
+type Stats struct {
+	ID         string
+	Name       string
+	CPUPercent float64
+	MemUsage   int64
+	MemLimit   int64
+	MemPercent float64
+	PIDs       int
+}
+
+var (
+	// statsInterval is how often StreamStats and WatchStats read the
+	// resource usage again.
+	statsInterval = 2 * time.Second
+)
+
+// GetContainerStats returns the current resource usage of a running
+// container.
+func GetContainerStats(container string) (Stats, error) {
+	if err := ensureContainerRunning(container); err != nil {
+		return Stats{}, err
+	}
+
+	containersStats, err := GetStats(context.Background(), []string{container})
+	if err != nil {
+		return Stats{}, err
+	}
+
+	if len(containersStats) != 1 {
+		return Stats{}, fmt.Errorf("failed to get the resource usage of container %s", container)
+	}
+
+	return containersStats[0], nil
+}
+
+// GetStats returns the current resource usage of several running containers
+// at once, which is quicker than asking for each of them. It fails if any of
+// them isn't running.
+func GetStats(ctx context.Context, containers []string) ([]Stats, error) {
+	if len(containers) == 0 {
+		return nil, nil
+	}
+
+	var stderr, stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "stats", "--no-stream", "--format", "json"}
+	args = append(args, containers...)
+
+	if err := run(ctx, nil, nil, &stdout, getStderr(&stderr), args...); err != nil {
+		return nil, errorWithStderr(err, &stderr)
+	}
+
+	stats, err := parseStats(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the resource usage of containers: %w", err)
+	}
+
+	return stats, nil
+}
+
+// StreamStats calls handler with the resource usage of a running container
+// every few seconds, until ctx is done. It returns nil when ctx is done, and
+// an error if the container isn't running or the usage can't be read.
+func StreamStats(ctx context.Context, container string, handler func(Stats)) error {
+	if err := ensureContainerRunning(container); err != nil {
+		return err
+	}
+
+	listContainers := func() ([]string, error) {
+		return []string{container}, nil
+	}
+
+	return WatchStats(ctx, listContainers, func(containersStats []Stats) error {
+		if len(containersStats) != 1 {
+			return fmt.Errorf("failed to get the resource usage of container %s", container)
+		}
+
+		handler(containersStats[0])
+		return nil
+	})
+}
+
+// WatchStats calls handler with the resource usage of the containers returned
+// by listContainers every few seconds, until ctx is done. The containers are
+// listed again every time, so that callers can follow containers that are
+// started later. It returns nil when ctx is done, and otherwise the first
+// error from listContainers, GetStats or handler.
+func WatchStats(ctx context.Context,
+	listContainers func() ([]string, error),
+	handler func([]Stats) error) error {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	for {
+		containers, err := listContainers()
+		if err != nil {
+			return err
+		}
+
+		containersStats, err := GetStats(ctx, containers)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		if err := handler(containersStats); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseStats parses the output of 'podman stats --format json', where all
+// values are human-readable strings, like "0.15%" or "12.5MB / 16.5GB".
+func parseStats(data []byte) ([]Stats, error) {
+	var raw []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		CPUPercent string `json:"cpu_percent"`
+		MemUsage   string `json:"mem_usage"`
+		MemPercent string `json:"mem_percent"`
+		PIDs       string `json:"pids"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	stats := make([]Stats, 0, len(raw))
+
+	for _, value := range raw {
+		cpuPercent, err := parsePercent(value.CPUPercent)
+		if err != nil {
+			return nil, err
+		}
+
+		memPercent, err := parsePercent(value.MemPercent)
+		if err != nil {
+			return nil, err
+		}
+
+		memUsage, memLimit, err := parseUsageAndLimit(value.MemUsage)
+		if err != nil {
+			return nil, err
+		}
+
+		var pids int
+		if value.PIDs != "" && value.PIDs != "--" {
+			pids, err = strconv.Atoi(value.PIDs)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number of PIDs %s", value.PIDs)
+			}
+		}
+
+		stats = append(stats, Stats{
+			ID:         value.ID,
+			Name:       value.Name,
+			CPUPercent: cpuPercent,
+			MemUsage:   memUsage,
+			MemLimit:   memLimit,
+			MemPercent: memPercent,
+			PIDs:       pids,
+		})
+	}
+
+	return stats, nil
+}
+
+func parsePercent(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "--" {
+		return 0, nil
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %s", value)
+	}
+
+	return percent, nil
+}
+
+func parseUsageAndLimit(value string) (int64, int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "--" {
+		return 0, 0, nil
+	}
+
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid usage %s", value)
+	}
+
+	usage, err := units.FromHumanSize(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid usage %s", value)
+	}
+
+	limit, err := units.FromHumanSize(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid limit %s", value)
+	}
+
+	return usage, limit, nil
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeStatsJSON = `[
+  {
+    "id": "4c9f5ce5f4d4",
+    "name": "fedora-toolbox-36",
+    "cpu_time": "1.5s",
+    "cpu_percent": "0.15%",
+    "avg_cpu": "0.15%",
+    "mem_usage": "12.5MB / 16.5GB",
+    "mem_percent": "0.08%",
+    "net_io": "1.2kB / 648B",
+    "block_io": "0B / 0B",
+    "pids": "3"
+  }
+]`
+
+func TestParseStats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected []Stats
+		errMsg   string
+	}{
+		{
+			name: "running container",
+			data: fakeStatsJSON,
+			expected: []Stats{
+				{
+					ID:         "4c9f5ce5f4d4",
+					Name:       "fedora-toolbox-36",
+					CPUPercent: 0.15,
+					MemUsage:   12500000,
+					MemLimit:   16500000000,
+					MemPercent: 0.08,
+					PIDs:       3,
+				},
+			},
+		},
+		{
+			name: "missing values",
+			data: `[{"id": "4c9f5ce5f4d4", "name": "fedora-toolbox-36",
+				"cpu_percent": "--", "mem_usage": "--", "mem_percent": "--", "pids": "--"}]`,
+			expected: []Stats{
+				{
+					ID:   "4c9f5ce5f4d4",
+					Name: "fedora-toolbox-36",
+				},
+			},
+		},
+		{
+			name:   "invalid percentage",
+			data:   `[{"cpu_percent": "a lot"}]`,
+			errMsg: "invalid percentage a lot",
+		},
+		{
+			name:   "invalid usage",
+			data:   `[{"mem_usage": "12.5MB"}]`,
+			errMsg: "invalid usage 12.5MB",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stats, err := parseStats([]byte(tc.data))
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, stats)
+		})
+	}
+}
+
+func fakeStatsPodman(call shelltest.Call) int {
+	switch call.Arg(3) {
+	case "inspect":
+		fmt.Fprintln(call.Stdout, `[{"State": {"Status": "running"}}]`)
+	case "stats":
+		fmt.Fprintln(call.Stdout, fakeStatsJSON)
+	}
+
+	return 0
+}
+
+func TestStreamStats(t *testing.T) {
+	interval := statsInterval
+	statsInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		statsInterval = interval
+	})
+
+	t.Run("running container", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, fakeStatsPodman)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var received []Stats
+		err := StreamStats(ctx, "fedora-toolbox-36", func(stats Stats) {
+			received = append(received, stats)
+			if len(received) == 3 {
+				cancel()
+			}
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, received, 3)
+		assert.Equal(t, "fedora-toolbox-36", received[0].Name)
+		assert.Equal(t, 3, received[0].PIDs)
+	})
+
+	t.Run("exited container", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"State": {"Status": "exited"}}]`))
+
+		called := false
+		err := StreamStats(context.Background(), "fedora-toolbox-36", func(Stats) {
+			called = true
+		})
+
+		assert.EqualError(t, err, "container fedora-toolbox-36 is not running")
+		assert.False(t, called)
+	})
+}
+
+func TestWatchStats(t *testing.T) {
+	interval := statsInterval
+	statsInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		statsInterval = interval
+	})
+
+	r := shelltest.SetUp(t, SetRunner, shelltest.Output(fakeStatsJSON))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listed := [][]string{{}, {"fedora-toolbox-36"}}
+	listContainers := func() ([]string, error) {
+		containers := listed[0]
+		if len(listed) > 1 {
+			listed = listed[1:]
+		}
+
+		return containers, nil
+	}
+
+	var received [][]Stats
+	err := WatchStats(ctx, listContainers, func(containersStats []Stats) error {
+		received = append(received, containersStats)
+		if len(received) == 2 {
+			cancel()
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, received, 2)
+	assert.Empty(t, received[0])
+	assert.Len(t, received[1], 1)
+
+	calls := r.Calls()
+	assert.Equal(t, []string{"--log-level error stats --no-stream --format json fedora-toolbox-36"}, calls)
+}
+
+func TestGetContainerStats(t *testing.T) {
+	t.Run("running container", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner, fakeStatsPodman)
+
+		stats, err := GetContainerStats("fedora-toolbox-36")
+		assert.NoError(t, err)
+		assert.Equal(t, 0.15, stats.CPUPercent)
+		assert.Equal(t, int64(12500000), stats.MemUsage)
+		assert.Equal(t, int64(16500000000), stats.MemLimit)
+	})
+
+	t.Run("never started container", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, shelltest.Output(`[{"State": {"Status": "created"}}]`))
+
+		_, err := GetContainerStats("fedora-toolbox-36")
+		assert.EqualError(t, err, "container fedora-toolbox-36 is not running")
+
+		calls := r.Calls()
+		assert.Len(t, calls, 1)
+	})
+}
+
+func TestGetStats(t *testing.T) {
+	t.Run("running containers", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, shelltest.Output(fakeStatsJSON))
+
+		stats, err := GetStats(context.Background(), []string{"fedora-toolbox-36", "fedora-toolbox-37"})
+		assert.NoError(t, err)
+		assert.Len(t, stats, 1)
+		assert.Equal(t, "fedora-toolbox-36", stats[0].Name)
+
+		calls := r.Calls()
+		assert.Equal(t,
+			[]string{"--log-level error stats --no-stream --format json fedora-toolbox-36 fedora-toolbox-37"},
+			calls)
+	})
+
+	t.Run("no containers", func(t *testing.T) {
+		r := shelltest.SetUp(t, SetRunner, shelltest.Exit(1))
+
+		stats, err := GetStats(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, stats)
+		assert.Empty(t, r.Calls())
+	})
+
+	t.Run("stopped container", func(t *testing.T) {
+		shelltest.SetUp(t, SetRunner,
+			shelltest.Fail("Error: cannot get stats: container fedora-toolbox-37 is not running\n", 125))
+
+		_, err := GetStats(context.Background(), []string{"fedora-toolbox-37"})
+		assert.EqualError(t, err,
+			"failed to invoke podman(1): cannot get stats: container fedora-toolbox-37 is not running")
+	})
+}
@@ -17,6 +17,7 @@
 package shell
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -26,6 +27,46 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Runner runs commands. It lets the callers of this package, like package
+// podman, be tested with a fake that doesn't run anything.
+type Runner interface {
+	// RunWithExitCode runs the command called name with the arguments arg
+	// and returns its exit code. The environment of the command is extended
+	// with env, which holds variables in the form "key=value". The command
+	// is killed if ctx is done before it finishes. An error is only returned
+	// if the command couldn't be run, or was killed.
+	RunWithExitCode(ctx context.Context,
+		name string,
+		env []string,
+		stdin io.Reader,
+		stdout, stderr io.Writer,
+		arg ...string) (int, error)
+}
+
+// NotFoundError is returned by Runners if the command couldn't be found.
+type NotFoundError struct {
+	Name string
+}
+
+func (err *NotFoundError) Error() string {
+	return fmt.Sprintf("%s(1) not found", err.Name)
+}
+
+// ExecRunner is the Runner that runs commands with package os/exec, like the
+// functions of this package do.
+type ExecRunner struct{}
+
+var _ Runner = ExecRunner{}
+
+func (ExecRunner) RunWithExitCode(ctx context.Context,
+	name string,
+	env []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+	arg ...string) (int, error) {
+	return runWithExitCode(ctx, name, env, stdin, stdout, stderr, arg...)
+}
+
 func Run(name string, stdin io.Reader, stdout, stderr io.Writer, arg ...string) error {
 	exitCode, err := RunWithExitCode(name, stdin, stdout, stderr, arg...)
 	if err != nil {
@@ -38,19 +79,36 @@ func Run(name string, stdin io.Reader, stdout, stderr io.Writer, arg ...string)
 }
 
 func RunWithExitCode(name string, stdin io.Reader, stdout, stderr io.Writer, arg ...string) (int, error) {
+	return runWithExitCode(context.Background(), name, nil, stdin, stdout, stderr, arg...)
+}
+
+func runWithExitCode(ctx context.Context,
+	name string,
+	env []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+	arg ...string) (int, error) {
 	logLevel := logrus.GetLevel()
 	if stderr == nil && logLevel >= logrus.DebugLevel {
 		stderr = os.Stderr
 	}
 
-	cmd := exec.Command(name, arg...)
+	cmd := exec.CommandContext(ctx, name, arg...)
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
 	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 1, fmt.Errorf("failed to invoke %s(1): %w", name, ctxErr)
+		}
+
 		if errors.Is(err, exec.ErrNotFound) {
-			return 1, fmt.Errorf("%s(1) not found", name)
+			return 1, &NotFoundError{Name: name}
 		}
 
 		var exitErr *exec.ExitError
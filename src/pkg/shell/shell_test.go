@@ -1,10 +1,12 @@
 package shell_test
 
 import (
+	"context"
 	"errors"
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/containers/toolbox/pkg/shell"
 	"github.com/sirupsen/logrus"
@@ -56,7 +58,7 @@ func TestShellRun(t *testing.T) {
 				useStdErr:   false,
 			},
 			expect: expect{
-				err:    errors.New("no-exist-executable(1) not found"),
+				err:    &shell.NotFoundError{Name: "no-exist-executable"},
 				stdout: nil,
 				stderr: nil,
 			},
@@ -179,7 +181,7 @@ func TestShellRunWithExitCode(t *testing.T) {
 				useStdErr:   false,
 			},
 			expect: expect{
-				err:    errors.New("no-exist-executable(1) not found"),
+				err:    &shell.NotFoundError{Name: "no-exist-executable"},
 				code:   1,
 				stdout: nil,
 				stderr: nil,
@@ -225,6 +227,49 @@ func TestShellRunWithExitCode(t *testing.T) {
 	}
 }
 
+func TestExecRunner(t *testing.T) {
+	var runner shell.Runner = shell.ExecRunner{}
+	var actualStdOut outputMock
+
+	exitCode, err := runner.RunWithExitCode(context.Background(),
+		"sh",
+		[]string{"TOOLBOX_TEST=toolbox test"},
+		nil,
+		&actualStdOut,
+		nil,
+		"-c",
+		"echo \"$TOOLBOX_TEST\"; exit 3")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, []byte("toolbox test\n"), actualStdOut.written)
+}
+
+func TestExecRunnerContext(t *testing.T) {
+	var runner shell.Runner = shell.ExecRunner{}
+
+	t.Run("FAIL_Deadline_Exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := runner.RunWithExitCode(ctx, "sleep", nil, nil, nil, nil, "10")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualError(t, err, "failed to invoke sleep(1): context deadline exceeded")
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+
+	t.Run("FAIL_Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := runner.RunWithExitCode(ctx, "sleep", []string{"TOOLBOX_TEST=1"}, nil, nil, nil, "10")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 // outputMock is a mock to ensure content written to stdout/stderr was correct
 type outputMock struct {
 	written []byte
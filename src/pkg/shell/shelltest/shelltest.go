@@ -0,0 +1,188 @@
+/*
+ * Copyright © 2026 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package shelltest provides a fake shell.Runner, so that the packages that
+// run commands, like package podman, can be tested without running anything.
+package shelltest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/shell"
+)
+
+// Call is a command run through a Runner.
+type Call struct {
+	// Ctx is done when the command should be killed.
+	Ctx context.Context
+
+	// Args holds the arguments, without the name of the command.
+	Args []string
+
+	// Env holds the variables that the environment is extended with, in
+	// the form "key=value".
+	Env []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Arg returns the argument at position i, counting from 1 like a shell
+// script does, or an empty string if there are fewer arguments.
+func (call Call) Arg(i int) string {
+	if i < 1 || i > len(call.Args) {
+		return ""
+	}
+
+	return call.Args[i-1]
+}
+
+// Getenv returns the value of the variable key in Env, or an empty string if
+// it isn't there.
+func (call Call) Getenv(key string) string {
+	for _, variable := range call.Env {
+		if strings.HasPrefix(variable, key+"=") {
+			return strings.TrimPrefix(variable, key+"=")
+		}
+	}
+
+	return ""
+}
+
+// Handler plays the part of a command and returns its exit code. A handler
+// that waits for call.Ctx to be done plays a command that gets killed, so it
+// must return a non-zero exit code then.
+type Handler func(call Call) int
+
+// Output returns a Handler that prints output on the standard output and
+// succeeds.
+func Output(output string) Handler {
+	return func(call Call) int {
+		fmt.Fprint(call.Stdout, output)
+		return 0
+	}
+}
+
+// Fail returns a Handler that prints stderr on the standard error and exits
+// with exitCode.
+func Fail(stderr string, exitCode int) Handler {
+	return func(call Call) int {
+		fmt.Fprint(call.Stderr, stderr)
+		return exitCode
+	}
+}
+
+// Exit returns a Handler that does nothing but exit with exitCode.
+func Exit(exitCode int) Handler {
+	return func(call Call) int {
+		return exitCode
+	}
+}
+
+// Hang is a Handler for a command that doesn't finish until it gets killed.
+func Hang(call Call) int {
+	<-call.Ctx.Done()
+	return 137
+}
+
+// Runner is a shell.Runner that calls a Handler instead of running commands,
+// and records their arguments. Without a Handler, commands aren't found.
+type Runner struct {
+	handler Handler
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var _ shell.Runner = &Runner{}
+
+// NewRunner returns a Runner that calls handler for every command.
+func NewRunner(handler Handler) *Runner {
+	return &Runner{handler: handler}
+}
+
+// SetUp makes the package, whose setter of its shell.Runner is setRunner, use
+// a new Runner with the given handler for the duration of the test.
+func SetUp(t testing.TB, setRunner func(shell.Runner) shell.Runner, handler Handler) *Runner {
+	r := NewRunner(handler)
+
+	previous := setRunner(r)
+	t.Cleanup(func() {
+		setRunner(previous)
+	})
+
+	return r
+}
+
+// RunWithExitCode records the arguments and calls the handler. Like
+// shell.ExecRunner, it returns an error wrapping ctx.Err() if the command
+// failed after ctx was done.
+func (r *Runner) RunWithExitCode(ctx context.Context,
+	name string,
+	env []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+	arg ...string) (int, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, strings.Join(arg, " "))
+	r.mu.Unlock()
+
+	if r.handler == nil {
+		return 1, &shell.NotFoundError{Name: name}
+	}
+
+	if stdin == nil {
+		stdin = &bytes.Buffer{}
+	}
+
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	call := Call{Ctx: ctx, Args: arg, Env: env, Stdin: stdin, Stdout: stdout, Stderr: stderr}
+	exitCode := r.handler(call)
+
+	if exitCode != 0 {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 1, fmt.Errorf("failed to invoke %s(1): %w", name, ctxErr)
+		}
+	}
+
+	return exitCode, nil
+}
+
+// Calls returns the arguments of the commands run so far, separated by
+// spaces, one string per command.
+func (r *Runner) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]string, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
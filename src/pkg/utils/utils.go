@@ -49,8 +49,19 @@ type Distro struct {
 	ParseRelease           ParseReleaseFunc
 }
 
+// ImageReference holds the components of an image reference in the format
+// REGISTRY/REPOSITORY[:TAG][@DIGEST].
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
 const (
 	containerNamePrefixFallback = "fedora-toolbox"
+	imageRegistryDefault        = "docker.io"
+	imageTagDefault             = "latest"
 	distroFallback              = "fedora"
 	idTruncLength               = 12
 	releaseFallback             = "37"
@@ -129,7 +140,15 @@ var (
 
 	ErrDistroWithoutRelease = errors.New("non-default distribution must specify release")
 
+	ErrImageReferenceInvalid = errors.New("image reference is invalid")
+
 	ErrImageWithoutBasename = errors.New("image does not have a basename")
+
+	imageDigestRegexp = regexp.MustCompile("^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$")
+
+	imageRepositoryRegexp = regexp.MustCompile("^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$")
+
+	imageTagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
 )
 
 func init() {
@@ -192,6 +211,32 @@ func EnsureXdgRuntimeDirIsSet(uid int) {
 	}
 }
 
+// FormatAge returns how long ago t was, like "5 minutes ago", or "-" if t is
+// the zero time. Times in the future, for example because of clock skew, are
+// treated as now.
+func FormatAge(t time.Time) string {
+	return formatAge(t, time.Now())
+}
+
+func formatAge(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return FormatDuration(now.Sub(t)) + " ago"
+}
+
+// FormatDuration returns a human readable approximation of d, like
+// "Less than a second", "45 seconds", "About a minute" or "3 days". It uses
+// the same wording as Podman, and doesn't depend on the locale.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	return units.HumanDuration(d)
+}
+
 func ForwardToHost() (int, error) {
 	envOptions := GetEnvOptionsForPreservedVariables()
 	toolboxPath := os.Getenv("TOOLBOX_PATH")
@@ -499,7 +544,7 @@ func GetSupportedDistros() []string {
 //
 // Examples: "5 minutes ago", "2 hours ago", "3 days ago"
 func HumanDuration(duration int64) string {
-	return units.HumanDuration(time.Since(time.Unix(duration, 0))) + " ago"
+	return FormatAge(time.Unix(duration, 0))
 }
 
 // ImageReferenceCanBeID checks if 'image' might be the ID of an image
@@ -573,6 +618,76 @@ func ImageReferenceHasDomain(image string) bool {
 	return true
 }
 
+// ParseImageReference splits an image reference into its registry,
+// repository, tag and digest.
+//
+// References without a registry are assumed to be on docker.io, where
+// single-component repositories live under 'library', and references without
+// a tag or a digest are assumed to refer to the 'latest' tag.
+func ParseImageReference(ref string) (ImageReference, error) {
+	var imageRef ImageReference
+	name := ref
+
+	if i := strings.IndexRune(name, '@'); i != -1 {
+		imageRef.Digest = name[i+1:]
+		name = name[:i]
+
+		if !imageDigestRegexp.MatchString(imageRef.Digest) {
+			return ImageReference{}, &ImageError{ref, ErrImageReferenceInvalid}
+		}
+	}
+
+	if ImageReferenceHasDomain(name) {
+		i := strings.IndexRune(name, '/')
+		imageRef.Registry = name[:i]
+		name = name[i+1:]
+	}
+
+	if i := strings.LastIndex(name, ":"); i != -1 {
+		imageRef.Tag = name[i+1:]
+		name = name[:i]
+
+		if !imageTagRegexp.MatchString(imageRef.Tag) {
+			return ImageReference{}, &ImageError{ref, ErrImageReferenceInvalid}
+		}
+	}
+
+	if !imageRepositoryRegexp.MatchString(name) {
+		return ImageReference{}, &ImageError{ref, ErrImageReferenceInvalid}
+	}
+
+	imageRef.Repository = name
+
+	if imageRef.Registry == "" {
+		imageRef.Registry = imageRegistryDefault
+	}
+
+	if imageRef.Registry == imageRegistryDefault && !strings.ContainsRune(imageRef.Repository, '/') {
+		imageRef.Repository = "library/" + imageRef.Repository
+	}
+
+	if imageRef.Tag == "" && imageRef.Digest == "" {
+		imageRef.Tag = imageTagDefault
+	}
+
+	return imageRef, nil
+}
+
+// String returns the fully qualified form of the image reference.
+func (imageRef ImageReference) String() string {
+	ref := imageRef.Registry + "/" + imageRef.Repository
+
+	if imageRef.Tag != "" {
+		ref = ref + ":" + imageRef.Tag
+	}
+
+	if imageRef.Digest != "" {
+		ref = ref + "@" + imageRef.Digest
+	}
+
+	return ref
+}
+
 func SetUpConfiguration() error {
 	logrus.Debug("Setting up configuration")
 
@@ -18,10 +18,45 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		ago      time.Duration
+		zero     bool
+		expected string
+	}{
+		{name: "zero", zero: true, expected: "-"},
+		{name: "future", ago: -5 * time.Second, expected: "Less than a second ago"},
+		{name: "now", expected: "Less than a second ago"},
+		{name: "one second", ago: time.Second, expected: "1 second ago"},
+		{name: "seconds", ago: 45 * time.Second, expected: "45 seconds ago"},
+		{name: "one minute", ago: 70 * time.Second, expected: "About a minute ago"},
+		{name: "minutes", ago: 5 * time.Minute, expected: "5 minutes ago"},
+		{name: "one hour", ago: 70 * time.Minute, expected: "About an hour ago"},
+		{name: "hours", ago: 5 * time.Hour, expected: "5 hours ago"},
+		{name: "days", ago: 3 * 24 * time.Hour, expected: "3 days ago"},
+		{name: "weeks", ago: 21 * 24 * time.Hour, expected: "3 weeks ago"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created time.Time
+			if !tc.zero {
+				created = now.Add(-tc.ago)
+			}
+
+			assert.Equal(t, tc.expected, formatAge(created, now))
+		})
+	}
+}
+
 func TestImageReferenceCanBeID(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -338,3 +373,83 @@ func TestParseRelease(t *testing.T) {
 		})
 	}
 }
+
+func TestParseImageReference(t *testing.T) {
+	const digest = "sha256:8b9affd1dbc261a7f586ed06a8fd993d09449a5ac79ebc7e80e86efdf3c223f6"
+
+	testCases := []struct {
+		ref      string
+		expected ImageReference
+		ok       bool
+	}{
+		{
+			ref:      "fedora",
+			expected: ImageReference{"docker.io", "library/fedora", "latest", ""},
+			ok:       true,
+		},
+		{
+			ref:      "fedora:37",
+			expected: ImageReference{"docker.io", "library/fedora", "37", ""},
+			ok:       true,
+		},
+		{
+			ref:      "toolbx/fedora-toolbox:37",
+			expected: ImageReference{"docker.io", "toolbx/fedora-toolbox", "37", ""},
+			ok:       true,
+		},
+		{
+			ref:      "registry.fedoraproject.org/fedora-toolbox:37",
+			expected: ImageReference{"registry.fedoraproject.org", "fedora-toolbox", "37", ""},
+			ok:       true,
+		},
+		{
+			ref:      "localhost/gegl",
+			expected: ImageReference{"localhost", "gegl", "latest", ""},
+			ok:       true,
+		},
+		{
+			ref:      "localhost:5000/toolbx/gegl:1.0",
+			expected: ImageReference{"localhost:5000", "toolbx/gegl", "1.0", ""},
+			ok:       true,
+		},
+		{
+			ref:      "registry.access.redhat.com/ubi8/toolbox@" + digest,
+			expected: ImageReference{"registry.access.redhat.com", "ubi8/toolbox", "", digest},
+			ok:       true,
+		},
+		{
+			ref:      "quay.io/toolbx/ubuntu-toolbox:22.04@" + digest,
+			expected: ImageReference{"quay.io", "toolbx/ubuntu-toolbox", "22.04", digest},
+			ok:       true,
+		},
+		{
+			ref: "",
+			ok:  false,
+		},
+		{
+			ref: "Fedora:37",
+			ok:  false,
+		},
+		{
+			ref: "fedora:",
+			ok:  false,
+		},
+		{
+			ref: "fedora@sha256:abc",
+			ok:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			imageRef, err := ParseImageReference(tc.ref)
+
+			if tc.ok {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, imageRef)
+			} else {
+				assert.ErrorIs(t, err, ErrImageReferenceInvalid)
+			}
+		})
+	}
+}